@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+const (
+	// captchaButton is the callback data prefix of the buttons under join captchas,
+	// followed by the ID of the newcomer and the chosen answer.
+	captchaButton = "captcha:"
+
+	// captchaInterval is how often expired captchas are checked.
+	captchaInterval = 15 * time.Second
+
+	// captchaChoices is how many answers a math captcha offers.
+	captchaChoices = 4
+)
+
+// Kinds of captchas
+const (
+	captchaModeButton = "button" // Press a button
+	captchaModeMath   = "math"   // Pick the sum of two numbers
+)
+
+// validateCaptchaConfig reports invalid bot.captcha settings when the captcha is enabled.
+func validateCaptchaConfig() error {
+	if !viper.GetBool("bot.captcha.enabled") {
+		return nil
+	}
+
+	switch mode := viper.GetString("bot.captcha.mode"); mode {
+	case "", captchaModeButton, captchaModeMath:
+	default:
+		return fmt.Errorf("unknown bot.captcha.mode %q, use %q or %q", mode, captchaModeButton, captchaModeMath)
+	}
+
+	if d, err := parseDuration(viper.GetString("bot.captcha.timeout")); err != nil || d <= 0 {
+		return fmt.Errorf("invalid bot.captcha.timeout %q, use a duration like \"5m\"", viper.GetString("bot.captcha.timeout"))
+	}
+
+	if viper.GetFloat64("bot.captcha.bonus") < 0 {
+		return fmt.Errorf("bot.captcha.bonus must not be negative")
+	}
+
+	return nil
+}
+
+// challenge restricts a newcomer and posts a captcha they have to solve within
+// bot.captcha.timeout, or they are kicked. It reports whether the captcha was posted.
+func (app *application) challenge(ctx context.Context, b *bot.Bot, chatID int64, user *models.User) bool {
+	timeout, _ := parseDuration(viper.GetString("bot.captcha.timeout"))
+
+	captcha := database.Captcha{
+		ChatID:    chatID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(timeout),
+	}
+
+	// Remember a restriction the newcomer already had, e.g. a mute they tried
+	// to shake off by leaving and rejoining, so it is restored afterwards
+	if member := chatMember(ctx, b, chatID, user.ID); member != nil && member.Type == models.ChatMemberTypeRestricted {
+		data, err := json.Marshal(restrictedPermissions(member.Restricted))
+		if err != nil {
+			log.Printf("Failed to encode restriction of newcomer %d: %v\n", user.ID, err)
+			return false
+		}
+		captcha.Restriction = string(data)
+		captcha.RestrictedUntil = int64(member.Restricted.UntilDate)
+	}
+
+	err := muteMember(ctx, b, chatID, user.ID, time.Time{})
+	if err != nil {
+		log.Printf("Failed to restrict newcomer %d in chat %d: %v\n", user.ID, chatID, err)
+		return false
+	}
+
+	var text string
+	var buttons []models.InlineKeyboardButton
+	data := captchaButton + strconv.FormatInt(user.ID, 10) + ":"
+
+	switch viper.GetString("bot.captcha.mode") {
+	case captchaModeMath:
+		x, y := randRange(1, 10), randRange(1, 10)
+		captcha.Answer = strconv.Itoa(x + y)
+		text = fmt.Sprintf("👋 Welcome %s! What is *%d + %d*? Answer within %s or you will be removed.", mention(user.ID, user), x, y, humanDuration(timeout))
+
+		for _, choice := range mathChoices(x + y) {
+			buttons = append(buttons, models.InlineKeyboardButton{Text: strconv.Itoa(choice), CallbackData: data + strconv.Itoa(choice)})
+		}
+	default:
+		captcha.Answer = "ok"
+		text = fmt.Sprintf("👋 Welcome %s! Press the button within %s to show you're human, or you will be removed.", mention(user.ID, user), humanDuration(timeout))
+		buttons = []models.InlineKeyboardButton{{Text: "✅ I'm human", CallbackData: data + captcha.Answer}}
+	}
+
+	markup := &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{buttons}}
+	msg, err := sendNotice(ctx, b, chatID, text, markup)
+	if err != nil {
+		log.Printf("Failed to send captcha: %v\n", err)
+		// Don't leave the newcomer muted with nothing to solve
+		if err := releaseNewcomer(ctx, b, captcha); err != nil {
+			log.Printf("Failed to unmute newcomer %d: %v\n", user.ID, err)
+		}
+		return false
+	}
+
+	captcha.MessageID = msg.ID
+	err = app.models.Captchas.Insert(ctx, &captcha)
+	if err != nil {
+		log.Printf("Failed to store captcha: %v\n", err)
+		// Without a stored captcha nobody could solve or expire it, so take it
+		// back and let the newcomer in
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: chatID, MessageID: msg.ID})
+		if err := releaseNewcomer(ctx, b, captcha); err != nil {
+			log.Printf("Failed to unmute newcomer %d: %v\n", user.ID, err)
+		}
+		return false
+	}
+	return true
+}
+
+// restrictedPermissions returns the permissions a restricted member has.
+func restrictedPermissions(r *models.ChatMemberRestricted) models.ChatPermissions {
+	return models.ChatPermissions{
+		CanSendMessages:       r.CanSendMessages,
+		CanSendAudios:         r.CanSendAudios,
+		CanSendDocuments:      r.CanSendDocuments,
+		CanSendPhotos:         r.CanSendPhotos,
+		CanSendVideos:         r.CanSendVideos,
+		CanSendVideoNotes:     r.CanSendVideoNotes,
+		CanSendVoiceNotes:     r.CanSendVoiceNotes,
+		CanSendPolls:          r.CanSendPolls,
+		CanSendOtherMessages:  r.CanSendOtherMessages,
+		CanAddWebPagePreviews: r.CanAddWebPagePreviews,
+		CanChangeInfo:         r.CanChangeInfo,
+		CanInviteUsers:        r.CanInviteUsers,
+		CanPinMessages:        r.CanPinMessages,
+		CanManageTopics:       r.CanManageTopics,
+	}
+}
+
+// releaseNewcomer lifts the restriction a captcha added. Newcomers who were
+// restricted before get that restriction back instead of the chat's defaults,
+// unless it is about to end anyway.
+func releaseNewcomer(ctx context.Context, b *bot.Bot, captcha database.Captcha) error {
+	if captcha.Restriction == "" {
+		return unmuteMember(ctx, b, captcha.ChatID, captcha.UserID)
+	}
+
+	var permissions models.ChatPermissions
+	if err := json.Unmarshal([]byte(captcha.Restriction), &permissions); err != nil {
+		return err
+	}
+
+	var until time.Time
+	if captcha.RestrictedUntil != 0 {
+		until = time.Unix(captcha.RestrictedUntil, 0)
+		// Telegram would make a restriction ending this soon permanent
+		if time.Until(until) < minSanction {
+			return unmuteMember(ctx, b, captcha.ChatID, captcha.UserID)
+		}
+	}
+
+	_, err := b.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+		ChatID:      captcha.ChatID,
+		UserID:      captcha.UserID,
+		Permissions: &permissions,
+		UntilDate:   untilDate(until),
+	})
+	return err
+}
+
+// mathChoices returns the answer and captchaChoices-1 wrong sums of two digits, shuffled.
+func mathChoices(answer int) []int {
+	choices := []int{answer}
+	for len(choices) < captchaChoices {
+		choice := randRange(2, 19)
+		if !slices.Contains(choices, choice) {
+			choices = append(choices, choice)
+		}
+	}
+
+	rand.Shuffle(len(choices), func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+	return choices
+}
+
+// captchaAnswer settles a captcha when the newcomer presses one of its buttons.
+// The right answer lifts the restriction and grants bot.captcha.bonus points,
+// a wrong one kicks the newcomer.
+func (app *application) captchaAnswer(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	if query.Message.Message == nil {
+		answerCallback(ctx, b, query, "This message is too old.")
+		return
+	}
+	notice := query.Message.Message
+	chatID := notice.Chat.ID
+
+	userPart, answer, _ := strings.Cut(strings.TrimPrefix(query.Data, captchaButton), ":")
+	userID, err := strconv.ParseInt(userPart, 10, 64)
+	if err != nil || userID != query.From.ID {
+		answerCallback(ctx, b, query, "This challenge is not for you.")
+		return
+	}
+
+	captcha, err := app.models.Captchas.Get(ctx, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch captcha: %v\n", err)
+		answerCallback(ctx, b, query, "Something went wrong, please try again.")
+		return
+	}
+
+	// Only the first press settles the captcha
+	settled, err := app.models.Captchas.Delete(ctx, chatID, userID)
+	if err != nil || captcha == nil || !settled {
+		answerCallback(ctx, b, query, "This challenge has expired.")
+		return
+	}
+
+	name := mention(userID, &query.From)
+	if answer != captcha.Answer {
+		app.failCaptcha(ctx, b, *captcha, fmt.Sprintf("❌ %s failed the captcha and was removed.", name))
+		answerCallback(ctx, b, query, "Wrong answer.")
+		return
+	}
+
+	err = releaseNewcomer(ctx, b, *captcha)
+	if err != nil {
+		log.Printf("Failed to lift captcha restriction of user %d in chat %d: %v\n", userID, chatID, err)
+		answerCallback(ctx, b, query, "I could not lift your restriction, please ask an admin.")
+		return
+	}
+
+	log.Printf("User %d solved the captcha in chat %d\n", userID, chatID)
+
+	text := fmt.Sprintf("✅ %s is verified, welcome!", name)
+	// The bonus is paid once per member, not every time they rejoin
+	if bonus := viper.GetFloat64("bot.captcha.bonus"); bonus > 0 {
+		first, err := app.models.Members.ClaimCaptchaBonus(ctx, chatID, userID)
+		if err != nil {
+			log.Printf("Failed to claim welcome bonus: %v\n", err)
+		} else if first {
+			if err := app.credit(ctx, chatID, userID, bonus, pointSources[13]); err != nil {
+				log.Printf("Failed to credit welcome bonus: %v\n", err)
+			} else {
+				text += fmt.Sprintf(" 🎁 +%.2f points.", bonus)
+			}
+		}
+	}
+
+	editCaptcha(ctx, b, chatID, captcha.MessageID, text)
+	if captcha.Restriction != "" {
+		answerCallback(ctx, b, query, "Thanks! Your earlier restrictions still apply.")
+	} else {
+		answerCallback(ctx, b, query, "Thanks, you can chat now!")
+	}
+
+	// Newcomers who had to solve a captcha are only welcomed once they did
+	app.greet(ctx, b, notice.Chat, &query.From, true)
+}
+
+// failCaptcha kicks a newcomer who failed their captcha and replaces the
+// challenge with text.
+func (app *application) failCaptcha(ctx context.Context, b *bot.Bot, captcha database.Captcha, text string) {
+	err := kickMember(ctx, b, captcha.ChatID, captcha.UserID)
+	if err != nil {
+		log.Printf("Failed to kick user %d from chat %d after the captcha: %v\n", captcha.UserID, captcha.ChatID, err)
+	} else {
+		log.Printf("User %d kicked from chat %d after failing the captcha\n", captcha.UserID, captcha.ChatID)
+	}
+
+	// Kicking clears restrictions, so an earlier mute is put back for when they rejoin
+	if captcha.Restriction != "" {
+		if err := releaseNewcomer(ctx, b, captcha); err != nil {
+			log.Printf("Failed to restore restriction of user %d in chat %d: %v\n", captcha.UserID, captcha.ChatID, err)
+		}
+	}
+
+	editCaptcha(ctx, b, captcha.ChatID, captcha.MessageID, text)
+}
+
+// editCaptcha replaces a challenge with text and removes its buttons.
+func editCaptcha(ctx context.Context, b *bot.Bot, chatID int64, messageID int, text string) {
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+		ParseMode: models.ParseModeMarkdownV1,
+	})
+	if err != nil {
+		log.Printf("Failed to update captcha message: %v\n", err)
+	}
+}
+
+// expireCaptchas kicks newcomers whose captcha expired at or before now.
+func (app *application) expireCaptchas(ctx context.Context, b *bot.Bot, now time.Time) {
+	captchas, err := app.models.Captchas.Expired(ctx, now)
+	if err != nil {
+		log.Printf("Failed to fetch expired captchas: %v\n", err)
+		return
+	}
+
+	for _, captcha := range captchas {
+		// The newcomer may have solved it in the meantime
+		settled, err := app.models.Captchas.Delete(ctx, captcha.ChatID, captcha.UserID)
+		if err != nil || !settled {
+			continue
+		}
+
+		var user *models.User
+		if member := chatMember(ctx, b, captcha.ChatID, captcha.UserID); member != nil {
+			user = memberUser(*member)
+		}
+
+		app.failCaptcha(ctx, b, captcha, fmt.Sprintf("⌛ %s did not solve the captcha in time and was removed.", mention(captcha.UserID, user)))
+	}
+}
@@ -15,28 +15,22 @@ import (
 )
 
 var (
-	// Define a map to store point values for each message type
-	pointMap = map[string]func() float64{
-		"text": func() float64 {
-			min := viper.GetInt("bot.point.text.min")
-			max := viper.GetInt("bot.point.text.max")
-			return float64(randRange(min, max))
-		},
-		"docment":   func() float64 { return viper.GetFloat64("bot.point.document") },
-		"photo":     func() float64 { return viper.GetFloat64("bot.point.photo") },
-		"sticker":   func() float64 { return viper.GetFloat64("bot.point.sticker") },
-		"audio":     func() float64 { return viper.GetFloat64("bot.point.audio") },
-		"animation": func() float64 { return viper.GetFloat64("bot.point.animation") },
-	}
-
 	// pointSources defines different ways users can earn points.
 	pointSources = map[int]string{
-		1: "chatting",
-		2: "doublePoints",
-		3: "gift",
-		4: "luckyBonus",
-		5: "boughtBoost",
-		6: "penalty",
+		1:  "chatting",
+		2:  "doublePoints",
+		3:  "gift",
+		4:  "luckyBonus",
+		5:  "boughtBoost",
+		6:  "penalty",
+		7:  "engagement",
+		8:  "reaction",
+		9:  "edited",
+		10: "purged",
+		11: "event",
+		12: "flood",
+		13: "welcome",
+		14: "report",
 	}
 )
 
@@ -89,12 +83,36 @@ func (app *application) help(ctx context.Context, b *bot.Bot, update *models.Upd
 	helpMessage := `*Available Commands:*
 
 */rank [daily|weekly|monthly]* - Show ranking based on activity.
+*/rank topic [daily|weekly|monthly]* - Show ranking of the current forum topic.
 */history* - Show your last 50 activity records.
 */stats* - Display your overall stats in the chat.
 */gift [userid amount]* - gift points to users.
 */gift [amount]* - reply to user(s) message.
-*/seize [userid amount]* - seize users points for rules violation.
-*/seize [amount]* - reply to users message whose points need to be seized.
+*/seize [userid amount reason]* - seize users points for rules violation, the reason is optional.
+*/seize [amount reason]* - reply to users message whose points need to be seized.
+*/warn [userid] [reason]* - warn a user, or reply to their message.
+*/warns [userid]* - list the warnings of a user, or your own.
+*/unwarn [userid]* - remove the latest warning of a user.
+*/resetwarns [userid]* - remove every warning of a user.
+*/mute [userid] [duration]* - mute a user, forever or for a while (e.g. 1h).
+*/unmute [userid]* - let a muted user talk again.
+*/kick [userid]* - remove a user from the chat.
+*/ban [userid]* - ban a user from the chat.
+*/tban [userid] [duration]* - ban a user for a while (e.g. 2d).
+*/unban [userid]* - let a banned user join again.
+*/setlog [channel_id|off]* - log admin actions to a channel.
+*/auditlog [userid|all] [n]* - show the latest admin actions, "export" sends them as a CSV file.
+*/report [reason]* - reply to a message to report it to the admins.
+*/reports [on|off]* - get new reports in private messages.
+*/setwelcome [template]* - set the message greeting new members.
+*/setgoodbye [template]* - set the message sent when members leave.
+*/greetings [on|off|delete seconds|rules link]* - show or change the greetings.
+*/filter [add|remove|list]* - manage the blocked words, expressions and links.
+*/purge* - reply to a message to delete it and every message after it.
+*/probation [duration messages rate|off]* - show or set how newcomers earn points.
+*/trust [userid]* - let a user skip the newcomer probation, or reply to their message.
+*/event* - start, stop and schedule chat-wide point multiplier events.
+*/topicpoints [multiplier]* - show or set the point multiplier of the current topic.
 */shop* - display all the items available in shop.
 */boost* - display users avilable boost.
 */buy [itemid]* - buy any item specified by item id.
@@ -112,7 +130,6 @@ _Use these commands to track your activity and rankings!_`
 // countMessage handles the logic for counting points based on message type
 func (app *application) countMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
 	msg := update.Message
-	userID := msg.From.ID
 	chatID := msg.Chat.ID
 
 	// deleteCmd determines if commands should be deleted after execution
@@ -136,62 +153,181 @@ func (app *application) countMessage(ctx context.Context, b *bot.Bot, update *mo
 				return
 			}
 		}
+
+		for i, user := range newMembers {
+			if user.IsBot {
+				continue
+			}
+
+			// Newcomers who joined on their own have to solve a captcha first,
+			// members added by someone else are vouched for
+			if msg.From != nil && msg.From.ID == user.ID && viper.GetBool("bot.captcha.enabled") {
+				perm, err := botRights(ctx, b, chatID)
+				if err != nil || perm == nil || !perm.CanRestrictMembers {
+					log.Printf("Cannot challenge newcomer %d in chat %d without the permission to restrict members\n", user.ID, chatID)
+				} else if app.challenge(ctx, b, chatID, &newMembers[i]) {
+					continue
+				}
+			}
+
+			app.greet(ctx, b, msg.Chat, &newMembers[i], true)
+		}
+		return
+	}
+
+	// Say goodbye to members who left on their own, not to removed ones
+	if left := msg.LeftChatMember; left != nil {
+		if !left.IsBot && msg.From != nil && msg.From.ID == left.ID {
+			app.greet(ctx, b, msg.Chat, left, false)
+		}
 		return
 	}
 
-	point := calculatePoints(msg)
+	// Bots, channels and anonymous admins are skipped or credited to a chat account
+	userID, ok := senderID(msg)
+	if !ok {
+		return
+	}
+
+	// Remember who wrote the message so reactions to it can be credited
+	err := app.models.Messages.Insert(ctx, &database.Message{ChatID: chatID, MessageID: msg.ID, UserID: userID})
+	if err != nil {
+		log.Printf("Failed to record message author: %v\n", err)
+	}
+
+	now := time.Now()
+
+	// Newcomers earn nothing or a reduced rate while on probation
+	rate, err := app.probationRate(ctx, chatID, userID, now)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	err = app.models.Members.CountMessage(ctx, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to count member message: %v\n", err)
+	}
+
+	// The message stays unscored, so editing it later earns nothing either
+	if rate == 0 {
+		return
+	}
+
+	// Remember every text so duplicates are caught even during the cooldown
+	recent := app.throttle.remember(chatID, userID, msg.Text, now)
+
+	// Messages sent during the cooldown earn nothing and bursts earn less
+	factor, ok := app.throttle.allow(chatID, userID, now)
+	if !ok {
+		return
+	}
+
+	// Reward the author of the message this one replies to, at the replier's rate
+	app.rewardEngagement(ctx, msg, rate*factor)
+
+	// Topics can earn more, less or nothing at all
+	thread := threadID(msg)
+	multiplier, err := app.topicMultiplier(ctx, chatID, thread)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	// scale is everything the points are multiplied by, kept so edits can be
+	// re-scored the same way. The duplicate check is part of it because the
+	// recent texts are gone by the time the message is edited, and so is the
+	// random roll, so an edit that changes nothing earns nothing.
+	scale := duplicateRule(msg.Text, recent) * factor * multiplier * rate
+	base := calculatePoints(msg, nil, false)
+	if base > 0 {
+		scale *= calculatePoints(msg, nil, true) / base
+	}
+	point := base * scale
 
 	p := &database.Point{
-		ChatID: chatID,
-		UserID: userID,
-		Source: pointSources[1],
-		Change: "gain",
+		ChatID:   chatID,
+		UserID:   userID,
+		Source:   pointSources[1],
+		Change:   "gain",
+		ThreadID: thread,
 	}
 
-	user, err := app.models.Users.Get(chatID, userID)
+	user, err := app.models.Users.Get(ctx, chatID, userID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrStatsNotFound, true, deleteCmd)
 		return
 	}
 
-	// If user doesn't exist, insert the point and user data for the first time
-	if user == nil {
-		err = app.models.Users.Insert(chatID, userID, point)
+	// Only existing users can have bought a boost
+	if user != nil {
+		boost, err := app.models.Users.ActiveBoost(ctx, userID, chatID)
 		if err != nil {
-			sendMessage(ctx, b, chatID, msg.ID, ErrUpdateUserFailed, true, deleteCmd)
+			sendMessage(ctx, b, chatID, msg.ID, ErrBoostUnavailable, true, deleteCmd)
 			return
 		}
 
-		p.Amount = point
-		p.Source = pointSources[1]
-
-		err = app.models.Points.Insert(p)
-		if err != nil {
-			sendMessage(ctx, b, chatID, msg.ID, ErrAddPointsFailed, true, deleteCmd)
-			return
+		if boost != nil {
+			switch boost.Type {
+			case "doublePoints":
+				point *= 2
+				scale *= 2
+				p.Source = pointSources[2]
+			case "luckyBonus":
+				bonus := calculateLuckyBonus(point)
+				if point > 0 {
+					scale *= (point + bonus) / point
+				}
+				point += bonus
+				p.Source = pointSources[4]
+			}
 		}
+	}
+
+	// Chat-wide events multiply on top of personal boosts
+	eventMultiplier, err := app.eventMultiplier(ctx, chatID, now)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
 		return
 	}
+	if eventMultiplier != 1 {
+		point *= eventMultiplier
+		scale *= eventMultiplier
+		p.Source = pointSources[11]
+	}
 
-	boost, err := app.models.Users.ActiveBoost(userID, chatID)
+	// Never award more than the hourly and daily earning caps allow
+	hourLeft, dayLeft, err := app.remainingEarnings(ctx, chatID, userID, now)
 	if err != nil {
-		sendMessage(ctx, b, chatID, msg.ID, ErrBoostUnavailable, true, deleteCmd)
+		sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
 		return
 	}
 
-	if boost != nil {
-		switch boost.Type {
-		case "doublePoints":
-			point *= 2
-			p.Source = pointSources[2]
-		case "luckyBonus":
-			bonus := calculateLuckyBonus(point)
-			point += bonus
-			p.Source = pointSources[4]
+	// The scale keeps only what was actually paid out, so an edit can't claim
+	// the part the caps held back
+	if capped := min(point, hourLeft, dayLeft); capped < point {
+		if capped > 0 {
+			scale *= capped / point
+		} else {
+			scale = 0
+		}
+		point = capped
+	}
+	if point <= 0 {
+		// Still mark the message as scored so a later edit can be re-scored
+		err = app.models.Messages.Score(ctx, chatID, msg.ID, 0, scale)
+		if err != nil {
+			log.Printf("Failed to record message points: %v\n", err)
 		}
+		return
 	}
 
-	err = app.models.Users.Update(chatID, userID, user.Points+point)
+	// If user doesn't exist, insert the user data for the first time
+	if user == nil {
+		err = app.models.Users.Insert(ctx, chatID, userID, point)
+	} else {
+		err = app.models.Users.Update(ctx, chatID, userID, user.Points+point)
+	}
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrUpdateUserFailed, true, deleteCmd)
 		return
@@ -199,11 +335,17 @@ func (app *application) countMessage(ctx context.Context, b *bot.Bot, update *mo
 
 	p.Amount = point
 
-	err = app.models.Points.Insert(p)
+	err = app.models.Points.Insert(ctx, p)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrAddPointsFailed, true, deleteCmd)
 		return
 	}
+
+	// Remember what the message earned so edits and purges can adjust it
+	err = app.models.Messages.Score(ctx, chatID, msg.ID, point, scale)
+	if err != nil {
+		log.Printf("Failed to record message points: %v\n", err)
+	}
 }
 
 // userStats retrieves and displays user statistics
@@ -224,7 +366,7 @@ func (app *application) userStats(ctx context.Context, b *bot.Bot, update *model
 		chatUser = update.Message.From
 	}
 
-	user, err := app.models.Users.Get(chatID, userID)
+	user, err := app.models.Users.Get(ctx, chatID, userID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrStatsNotFound, true, deleteCmd)
 		return
@@ -235,8 +377,14 @@ func (app *application) userStats(ctx context.Context, b *bot.Bot, update *model
 		return
 	}
 
+	_, remainingToday, err := app.remainingEarnings(ctx, chatID, userID, time.Now())
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrStatsNotFound, true, deleteCmd)
+		return
+	}
+
 	// Send the nicely formatted message
-	msg := formatUserDetails(chatUser.FirstName, chatUser.Username, user)
+	msg := formatUserDetails(chatUser.FirstName, chatUser.Username, user, remainingToday)
 	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
 }
 
@@ -245,6 +393,12 @@ func (app *application) topUsers(ctx context.Context, b *bot.Bot, update *models
 	// Trim and clean the input after "/rank"
 	rankType := strings.ToLower(strings.TrimSpace(strings.Replace(update.Message.Text, "/rank", "", 1)))
 
+	// "/rank topic [period]" ranks the users of the current forum topic
+	if rest, ok := strings.CutPrefix(rankType, "topic"); ok {
+		app.topicRanking(ctx, b, update, strings.TrimSpace(rest))
+		return
+	}
+
 	validTypes := map[string]bool{
 		"daily":   true,
 		"weekly":  true,
@@ -265,7 +419,7 @@ func (app *application) topUsers(ctx context.Context, b *bot.Bot, update *models
 
 	rankingLimit := 20
 
-	points, err := app.models.Points.Ranking(chatID, rankingLimit, rankType)
+	points, err := app.models.Points.Ranking(ctx, chatID, rankingLimit, rankType)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrNoRankingsAvailable, true, deleteCmd)
 		return
@@ -309,7 +463,7 @@ func (app *application) pointHistory(ctx context.Context, b *bot.Bot, update *mo
 	// deleteCmd determines if commands should be deleted after execution
 	deleteCmd := viper.GetBool("bot.deleteCommand")
 
-	history, err := app.models.Points.History(chatID, userID, historyLimit)
+	history, err := app.models.Points.History(ctx, chatID, userID, historyLimit)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrHistoryUnavailable, true, deleteCmd)
 		return
@@ -402,7 +556,7 @@ func (app *application) gift(ctx context.Context, b *bot.Bot, update *models.Upd
 	}
 
 	// User who is giving the gift
-	issuer, err := app.models.Users.Get(chatID, update.Message.From.ID)
+	issuer, err := app.models.Users.Get(ctx, chatID, update.Message.From.ID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrNoPointsEarned, true, deleteCmd)
 		return
@@ -421,7 +575,7 @@ func (app *application) gift(ctx context.Context, b *bot.Bot, update *models.Upd
 	}
 
 	// User who is going to receive the gift
-	receiver, err := app.models.Users.Get(chatID, receiverID)
+	receiver, err := app.models.Users.Get(ctx, chatID, receiverID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrUserNotFound, true, deleteCmd)
 		return
@@ -441,21 +595,21 @@ func (app *application) gift(ctx context.Context, b *bot.Bot, update *models.Upd
 	}
 
 	// Update total points of issuer
-	err = app.models.Users.Update(chatID, update.Message.From.ID, issuer.Points-float64(giftAmount))
+	err = app.models.Users.Update(ctx, chatID, update.Message.From.ID, issuer.Points-float64(giftAmount))
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrUpdateUserFailed, true, deleteCmd)
 		return
 	}
 	p.UserID = update.Message.From.ID
 
-	err = app.models.Points.Insert(p)
+	err = app.models.Points.Insert(ctx, p)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrAddPointsFailed, true, deleteCmd)
 		return
 	}
 
 	// Update total points of receiver
-	err = app.models.Users.Update(chatID, receiverID, receiver.Points+float64(giftAmount))
+	err = app.models.Users.Update(ctx, chatID, receiverID, receiver.Points+float64(giftAmount))
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrUpdateUserFailed, true, deleteCmd)
 		return
@@ -465,7 +619,7 @@ func (app *application) gift(ctx context.Context, b *bot.Bot, update *models.Upd
 	p.Change = "gain"
 
 	// Add gift points to the user
-	err = app.models.Points.Insert(p)
+	err = app.models.Points.Insert(ctx, p)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrAddPointsFailed, true, deleteCmd)
 		return
@@ -479,7 +633,7 @@ func (app *application) gift(ctx context.Context, b *bot.Bot, update *models.Upd
 		Timestamp:  time.Now(),
 	}
 
-	err = app.models.Gifts.Insert(gft)
+	err = app.models.Gifts.Insert(ctx, gft)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrAddPointsFailed, true, deleteCmd)
 		return
@@ -498,7 +652,7 @@ func (app *application) shop(ctx context.Context, b *bot.Bot, update *models.Upd
 	// deleteCmd determines if commands should be deleted after execution
 	deleteCmd := viper.GetBool("bot.deleteCommand")
 
-	items, err := app.models.Shop.Items()
+	items, err := app.models.Shop.Items(ctx)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrShopEmpty, true, deleteCmd)
 		return
@@ -530,7 +684,7 @@ func (app *application) buyItem(ctx context.Context, b *bot.Bot, update *models.
 		return
 	}
 
-	buyer, err := app.models.Users.Get(chatID, userID)
+	buyer, err := app.models.Users.Get(ctx, chatID, userID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrUserNotFound, true, deleteCmd)
 		return
@@ -550,7 +704,7 @@ func (app *application) buyItem(ctx context.Context, b *bot.Bot, update *models.
 
 	//Check if boost already exist or not
 	// users can buy different type of boost simultaneously but not the same one
-	boost, err := app.models.Users.ActiveBoost(userID, chatID)
+	boost, err := app.models.Users.ActiveBoost(ctx, userID, chatID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrBoostUnavailable, true, deleteCmd)
 		return
@@ -564,7 +718,7 @@ func (app *application) buyItem(ctx context.Context, b *bot.Bot, update *models.
 		return
 	}
 
-	itm, err := app.models.Shop.Get(itemID)
+	itm, err := app.models.Shop.Get(ctx, itemID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrItemNotFound, true, deleteCmd)
 		return
@@ -581,21 +735,21 @@ func (app *application) buyItem(ctx context.Context, b *bot.Bot, update *models.
 	}
 
 	//buy the item
-	err = app.models.Shop.Buy(userID, chatID, itm.ID, itm.Type, itm.Duration)
+	err = app.models.Shop.Buy(ctx, userID, chatID, itm.ID, itm.Type, itm.Duration)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrItemPurchaseFailed, true, deleteCmd)
 		return
 	}
 
 	//after successfully buying the item deduct price from the buyer
-	err = app.models.Users.Update(chatID, userID, buyer.Points-itm.Price)
+	err = app.models.Users.Update(ctx, chatID, userID, buyer.Points-itm.Price)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrUpdateUserFailed, true, deleteCmd)
 		return
 	}
 
 	//get boost by item id
-	boost, err = app.models.Users.GetBoostByItem(userID, chatID, itm.ID)
+	boost, err = app.models.Users.GetBoostByItem(ctx, userID, chatID, itm.ID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrBoostUnavailable, true, deleteCmd)
 		return
@@ -615,7 +769,7 @@ func (app *application) buyItem(ctx context.Context, b *bot.Bot, update *models.
 	}
 
 	//update points history
-	err = app.models.Points.Insert(p)
+	err = app.models.Points.Insert(ctx, p)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrAddPointsFailed, true, deleteCmd)
 		return
@@ -637,7 +791,7 @@ func (app *application) boost(ctx context.Context, b *bot.Bot, update *models.Up
 	// deleteCmd determines if commands should be deleted after execution
 	deleteCmd := viper.GetBool("bot.deleteCommand")
 
-	boost, err := app.models.Users.ActiveBoost(userID, chatID)
+	boost, err := app.models.Users.ActiveBoost(ctx, userID, chatID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msg.ID, ErrBoostUnavailable, true, deleteCmd)
 		return
@@ -651,13 +805,15 @@ func (app *application) boost(ctx context.Context, b *bot.Bot, update *models.Up
 	sendMessage(ctx, b, chatID, msg.ID, formatBoost(boost), true, deleteCmd)
 }
 
-// seize users bonus points as penalty
+// seize users bonus points as penalty, with an optional reason
+// (e.g.): "/seize userid 10 spamming" || reply with "/seize 10 spamming"
 func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Update) {
 	chatID := update.Message.Chat.ID
 	msgId := update.Message.ID
 	var (
 		seizeAmount int
 		userID      int64
+		reason      string
 	)
 	seize := strings.TrimSpace(strings.Replace(update.Message.Text, "/seize", "", 1))
 	parts := strings.Fields(seize)
@@ -669,9 +825,9 @@ func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Up
 		// If user replied to a message, extract the user ID from the replied message
 		userID = update.Message.ReplyToMessage.From.ID
 
-		// Ensure the user provided a gift amount
-		if len(parts) != 1 {
-			msg := "Usage: Reply to a users message with `/seize amount`."
+		// Ensure the user provided an amount
+		if len(parts) < 1 {
+			msg := "Usage: Reply to a users message with `/seize amount [reason]`."
 			sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
 			return
 		}
@@ -683,9 +839,10 @@ func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Up
 			return
 		}
 		seizeAmount = parsedAmount
+		reason = strings.Join(parts[1:], " ")
 	} else {
-		if len(parts) != 2 {
-			msg := "Usage: `/seize user_id amount`."
+		if len(parts) < 2 {
+			msg := "Usage: `/seize user_id amount [reason]`."
 			sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
 			return
 		}
@@ -705,6 +862,7 @@ func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Up
 			return
 		}
 		seizeAmount = parsedAmount
+		reason = strings.Join(parts[2:], " ")
 	}
 
 	// Check if user is trying to deduct own points then skip
@@ -727,7 +885,7 @@ func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Up
 	}
 
 	//Check whose point we are going to seize exist or not
-	perpetrator, err := app.models.Users.Get(chatID, userID)
+	perpetrator, err := app.models.Users.Get(ctx, chatID, userID)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrUserNotFound, true, deleteCmd)
 		return
@@ -745,7 +903,7 @@ func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Up
 	}
 
 	//deduct the points
-	err = app.models.Users.Update(chatID, userID, perpetrator.Points-float64(seizeAmount))
+	err = app.models.Users.Update(ctx, chatID, userID, perpetrator.Points-float64(seizeAmount))
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrUpdateUserFailed, true, deleteCmd)
 		return
@@ -757,15 +915,56 @@ func (app *application) seize(ctx context.Context, b *bot.Bot, update *models.Up
 		Amount: float64(seizeAmount),
 		Source: pointSources[6],
 		Change: "loss",
+		Reason: reason,
 	}
 
 	//update the logs
-	err = app.models.Points.Insert(p)
+	err = app.models.Points.Insert(ctx, p)
 	if err != nil {
 		sendMessage(ctx, b, chatID, msgId, ErrAddPointsFailed, true, deleteCmd)
 		return
 	}
 
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "seize",
+		ActorID:  update.Message.From.ID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+		Target:   lookupUser(ctx, b, update.Message, userID),
+		Amount:   float64(seizeAmount),
+		Reason:   reason,
+	})
+
+	notifyPenalty(ctx, b, update.Message.Chat, userID, float64(seizeAmount), reason)
+
 	msg := fmt.Sprintf("%d points have been deducted from user ID: %d.", seizeAmount, userID)
+	if reason != "" {
+		msg += fmt.Sprintf("\n📝 Reason: %s", escapeMarkdown(reason))
+	}
 	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
 }
+
+// notifyPenalty tells a member in a private message that they lost points and
+// how to appeal, as set in bot.seize.appeal. Telegram only delivers it if the
+// member started a chat with the bot, otherwise it is silently skipped.
+func notifyPenalty(ctx context.Context, b *bot.Bot, chat models.Chat, userID int64, amount float64, reason string) {
+	if reason == "" {
+		reason = "no reason given"
+	}
+
+	appeal := viper.GetString("bot.seize.appeal")
+	if appeal == "" {
+		appeal = "contact the admins of the chat"
+	}
+
+	text := fmt.Sprintf("➖ An admin of *%s* took %.2f points from you.\n📝 Reason: %s\n\nIf you think this is a mistake, %s.", escapeMarkdown(chat.Title), amount, escapeMarkdown(reason), appeal)
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    userID,
+		Text:      text,
+		ParseMode: models.ParseModeMarkdownV1,
+	})
+	if err != nil {
+		log.Printf("Could not notify user %d of their penalty: %v\n", userID, err)
+	}
+}
@@ -28,4 +28,29 @@ const (
 	ErrUnknownError            = "**An unexpected error occurred. Please contact support if this persists.**"
 	ErrCannotDeductOwnPoints   = "You cannot deduct points from yourself."
 	ErrCannotDeductAdminPoints = "You cannot deduct points from another administrator."
+	ErrBotCannotDelete         = "**I need the permission to delete messages for this.**"
+	ErrPurgeTooLarge           = "**You can purge at most %d messages at once.**"
+	ErrInvalidMultiplier       = "The multiplier must be a number between 0 and 10."
+	ErrInvalidEventMultiplier  = "The multiplier must be a positive number up to `10x`, like `2x` or `1.5x`."
+	ErrInvalidEventDuration    = "The event duration must be like `30m`, `2h` or `2d`, and at most 7 days."
+	ErrNoRunningEvent          = "**There is no event running right now.**"
+	ErrNoEvents                = "**There are no running or recurring events.**"
+	ErrCannotWarnSelf          = "You cannot warn yourself."
+	ErrCannotWarnAdmin         = "You cannot warn another administrator."
+	ErrCannotSanctionSelf      = "You cannot use this command on yourself or on me."
+	ErrCannotSanctionAdmin     = "You cannot use this command on another administrator."
+	ErrBotCannotRestrict       = "**I need the permission to ban users for this.**"
+	ErrSanctionFailed          = "**Telegram refused the action. Please check the user and my admin rights.**"
+	ErrInvalidProbationRate    = "The probation rate must be between 0 and 1."
+	ErrScheduleNotFound        = "**No recurring event found with the specified ID.**"
+	ErrNoFilters               = "**There are no filters in this chat.**"
+	ErrFilterNotFound          = "**No filter found with the specified ID.**"
+	ErrInvalidTemplate         = "**Telegram can't display this template, check its formatting.**"
+	ErrCannotReportSelf        = "You cannot report your own message."
+	ErrCannotReportAdmin       = "You cannot report messages of administrators or bots."
+	ErrAlreadyReported         = "This message was already reported."
+	ErrCannotPostToLog         = "**I can't post there. Add me to the channel as an admin who can post messages.**"
+	ErrNotLogAdmin             = "You must be an admin of the log channel."
+	ErrInvalidSanctionDuration = "The duration must be between `30s` and `366d`, Telegram makes anything else permanent."
+	ErrNoAuditEntries          = "**No admin actions were recorded yet.**"
 )
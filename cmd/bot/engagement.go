@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/spf13/viper"
+)
+
+// rewardEngagement gives the author of the message that msg replies to a bonus,
+// so members who start good discussions are rewarded too. The bonus is scaled
+// by rate, the share of the usual points the replier earns right now, so
+// members on probation or in a burst can't mint full points for others.
+//
+// Configured under bot.engagement:
+// - reply: points the author gets for a reply.
+// - quote: points the author gets instead when the reply quotes part of the message.
+// - pairDailyCap: max rewarded replies from one member to another per day.
+func (app *application) rewardEngagement(ctx context.Context, msg *models.Message, rate float64) {
+	reply := msg.ReplyToMessage
+	if reply == nil {
+		return
+	}
+
+	// Messages inside a forum topic reply to the service message that created
+	// it, which is not engagement
+	if reply.ForumTopicCreated != nil {
+		return
+	}
+
+	replierID, ok := senderID(msg)
+	if !ok {
+		return
+	}
+
+	// Replying to yourself or to a sender that can't earn gives nothing
+	authorID, ok := senderID(reply)
+	if !ok || authorID == replierID {
+		return
+	}
+
+	bonus := viper.GetFloat64("bot.engagement.reply")
+	if msg.Quote != nil && viper.IsSet("bot.engagement.quote") {
+		bonus = viper.GetFloat64("bot.engagement.quote")
+	}
+	bonus *= rate
+	if bonus <= 0 {
+		return
+	}
+
+	allowed, err := app.models.Engagements.Inc(ctx, msg.Chat.ID, replierID, authorID, viper.GetInt("bot.engagement.pairDailyCap"), time.Now())
+	if err != nil {
+		log.Printf("Failed to check engagement cap: %v\n", err)
+		return
+	}
+	if !allowed {
+		return
+	}
+
+	err = app.credit(ctx, msg.Chat.ID, authorID, bonus, pointSources[7])
+	if err != nil {
+		log.Printf("Failed to reward engagement for user %d: %v\n", authorID, err)
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+const (
+	// maxEventDuration is the longest a one-off event may run.
+	maxEventDuration = 7 * 24 * time.Hour
+
+	// maxEventMultiplier is the highest multiplier an event can have.
+	maxEventMultiplier = 10
+)
+
+// eventUsage explains the /event subcommands.
+const eventUsage = "Usage:\n" +
+	"`/event` - list running and recurring events.\n" +
+	"`/event start 2x 2h` - start a 2x event for 2 hours.\n" +
+	"`/event stop` - stop the running one-off events, schedules keep running.\n" +
+	"`/event schedule fri 20:00-22:00 1.5x` - run a 1.5x event every Friday.\n" +
+	"`/event unschedule id` - remove a recurring event."
+
+// eventLocation returns the time zone recurring events are scheduled in,
+// configured with bot.events.timezone (UTC by default).
+func eventLocation() *time.Location {
+	loc, err := time.LoadLocation(viper.GetString("bot.events.timezone"))
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// validateEventConfig reports an unknown bot.events.timezone at startup
+// instead of silently scheduling events in UTC.
+func validateEventConfig() error {
+	if _, err := time.LoadLocation(viper.GetString("bot.events.timezone")); err != nil {
+		return fmt.Errorf("invalid bot.events.timezone: %v", err)
+	}
+	return nil
+}
+
+// eventMultiplier returns the point multiplier of the events running in a chat
+// at now. Overlapping events don't stack, the highest multiplier wins.
+// It returns 1 when no event is running.
+func (app *application) eventMultiplier(ctx context.Context, chatID int64, now time.Time) (float64, error) {
+	multiplier := 1.0
+	running := false
+
+	events, err := app.models.Events.Active(ctx, chatID, now)
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		if !running || event.Multiplier > multiplier {
+			multiplier, running = event.Multiplier, true
+		}
+	}
+
+	schedules, err := app.models.Events.Schedules(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+	for _, schedule := range schedules {
+		if scheduleActive(schedule, now) && (!running || schedule.Multiplier > multiplier) {
+			multiplier, running = schedule.Multiplier, true
+		}
+	}
+
+	return multiplier, nil
+}
+
+// scheduleActive reports whether a recurring event is running at now.
+// Events that end before they start run past midnight into the next day.
+func scheduleActive(schedule database.Schedule, now time.Time) bool {
+	start, err := parseClock(schedule.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(schedule.EndTime)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(eventLocation())
+	minute := local.Hour()*60 + local.Minute()
+	weekday := local.Weekday()
+
+	if start < end {
+		return weekday == schedule.Weekday && minute >= start && minute < end
+	}
+	return (weekday == schedule.Weekday && minute >= start) ||
+		(weekday == (schedule.Weekday+1)%7 && minute < end)
+}
+
+// parseClock parses a time of day like "20:00" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseMultiplier parses a multiplier like "2x" or "1.5". It must be positive
+// and at most maxEventMultiplier.
+func parseMultiplier(s string) (float64, error) {
+	multiplier, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "x"), 64)
+	// ParseFloat accepts "NaN" and "Inf", and NaN passes every comparison
+	if err != nil || math.IsNaN(multiplier) || math.IsInf(multiplier, 0) || multiplier <= 0 || multiplier > maxEventMultiplier {
+		return 0, fmt.Errorf("invalid multiplier %q", s)
+	}
+	return multiplier, nil
+}
+
+// parseWeekday parses a day like "fri", "Friday" or "fridays".
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	if len(s) >= 3 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			name := strings.ToLower(day.String())
+			if strings.HasPrefix(name, s) || s == name+"s" {
+				return day, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
+// formatMultiplier formats a multiplier like "2x" or "1.5x".
+func formatMultiplier(multiplier float64) string {
+	return strconv.FormatFloat(multiplier, 'f', -1, 64) + "x"
+}
+
+// event manages chat-wide point multiplier events.
+// (e.g.): "/event start 2x 2h" || "/event schedule fri 20:00-22:00 1.5x"
+func (app *application) event(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	parts := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/event", "", 1)))
+	if len(parts) == 0 {
+		parts = []string{"list"}
+	}
+
+	switch {
+	case parts[0] == "list" && len(parts) == 1:
+		app.listEvents(ctx, b, update)
+
+	case parts[0] == "start" && len(parts) == 3:
+		multiplier, err := parseMultiplier(parts[1])
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidEventMultiplier, true, deleteCmd)
+			return
+		}
+
+		duration, err := parseDuration(parts[2])
+		if err != nil || duration <= 0 || duration > maxEventDuration {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidEventDuration, true, deleteCmd)
+			return
+		}
+
+		now := time.Now()
+		event := &database.Event{
+			ChatID:     chatID,
+			Multiplier: multiplier,
+			StartsAt:   now,
+			EndsAt:     now.Add(duration),
+			CreatedBy:  update.Message.From.ID,
+		}
+
+		err = app.models.Events.Insert(ctx, event)
+		if err != nil {
+			log.Printf("Failed to start event: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+
+		log.Printf("Event %d started in chat %d by %d: %s for %s\n", event.ID, chatID, event.CreatedBy, formatMultiplier(multiplier), duration)
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:   "event start",
+			ActorID:  update.Message.From.ID,
+			Actor:    update.Message.From,
+			Duration: duration,
+			Details:  fmt.Sprintf("Event %d: %s points", event.ID, formatMultiplier(multiplier)),
+		})
+
+		msg := fmt.Sprintf("🎉 *%s event started!* Every message earns %s points for the next %s.",
+			formatMultiplier(multiplier), formatMultiplier(multiplier), humanDuration(duration))
+		sendMessage(ctx, b, chatID, msgId, msg, false, deleteCmd)
+
+	case parts[0] == "stop" && len(parts) == 1:
+		now := time.Now()
+		n, err := app.models.Events.Stop(ctx, chatID, now)
+		if err != nil {
+			log.Printf("Failed to stop events: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+
+		// Stopping only ends one-off events, recurring ones keep running
+		schedules, err := app.models.Events.Schedules(ctx, chatID)
+		if err != nil {
+			log.Printf("Failed to fetch event schedules: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+		var running *database.Schedule
+		for i := range schedules {
+			if scheduleActive(schedules[i], now) {
+				running = &schedules[i]
+				break
+			}
+		}
+
+		if n == 0 {
+			if running != nil {
+				msg := fmt.Sprintf("Only one-off events can be stopped. Recurring event #%d is running, remove it with `/event unschedule %d`.", running.ID, running.ID)
+				sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+				return
+			}
+			sendMessage(ctx, b, chatID, msgId, ErrNoRunningEvent, true, deleteCmd)
+			return
+		}
+
+		log.Printf("Events stopped in chat %d by %d\n", chatID, update.Message.From.ID)
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:  "event stop",
+			ActorID: update.Message.From.ID,
+			Actor:   update.Message.From,
+		})
+		msg := "🏁 *The event has ended.* Points are back to normal."
+		if running != nil {
+			msg = fmt.Sprintf("🏁 *The event has ended.* Recurring event #%d keeps running, remove it with `/event unschedule %d`.", running.ID, running.ID)
+		}
+		sendMessage(ctx, b, chatID, msgId, msg, false, deleteCmd)
+
+	case parts[0] == "schedule" && len(parts) == 4:
+		weekday, err := parseWeekday(parts[1])
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, eventUsage, true, deleteCmd)
+			return
+		}
+
+		// Accept both "20:00-22:00" and "20:00–22:00"
+		start, end, ok := strings.Cut(strings.ReplaceAll(parts[2], "–", "-"), "-")
+		if !ok {
+			sendMessage(ctx, b, chatID, msgId, eventUsage, true, deleteCmd)
+			return
+		}
+		startMin, err := parseClock(start)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, eventUsage, true, deleteCmd)
+			return
+		}
+		endMin, err := parseClock(end)
+		if err != nil || endMin == startMin {
+			sendMessage(ctx, b, chatID, msgId, eventUsage, true, deleteCmd)
+			return
+		}
+
+		multiplier, err := parseMultiplier(parts[3])
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidEventMultiplier, true, deleteCmd)
+			return
+		}
+
+		schedule := &database.Schedule{
+			ChatID:     chatID,
+			Weekday:    weekday,
+			StartTime:  fmt.Sprintf("%02d:%02d", startMin/60, startMin%60),
+			EndTime:    fmt.Sprintf("%02d:%02d", endMin/60, endMin%60),
+			Multiplier: multiplier,
+			CreatedBy:  update.Message.From.ID,
+		}
+
+		err = app.models.Events.InsertSchedule(ctx, schedule)
+		if err != nil {
+			log.Printf("Failed to schedule event: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+
+		log.Printf("Event schedule %d added in chat %d by %d\n", schedule.ID, chatID, schedule.CreatedBy)
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:  "event schedule",
+			ActorID: update.Message.From.ID,
+			Actor:   update.Message.From,
+			Details: fmt.Sprintf("Schedule %d: %s points every %s", schedule.ID, formatMultiplier(multiplier), formatSchedule(*schedule)),
+		})
+
+		msg := fmt.Sprintf("📅 Scheduled a *%s* event every %s (ID `%d`).", formatMultiplier(multiplier), formatSchedule(*schedule), schedule.ID)
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+
+	case parts[0] == "unschedule" && len(parts) == 2:
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, eventUsage, true, deleteCmd)
+			return
+		}
+
+		deleted, err := app.models.Events.DeleteSchedule(ctx, chatID, id)
+		if err != nil {
+			log.Printf("Failed to remove event schedule: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+		if !deleted {
+			sendMessage(ctx, b, chatID, msgId, ErrScheduleNotFound, true, deleteCmd)
+			return
+		}
+
+		log.Printf("Event schedule %d removed in chat %d by %d\n", id, chatID, update.Message.From.ID)
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:  "event unschedule",
+			ActorID: update.Message.From.ID,
+			Actor:   update.Message.From,
+			Details: fmt.Sprintf("Schedule %d", id),
+		})
+		sendMessage(ctx, b, chatID, msgId, "🗑 The recurring event was removed.", true, deleteCmd)
+
+	default:
+		sendMessage(ctx, b, chatID, msgId, eventUsage, true, deleteCmd)
+	}
+}
+
+// listEvents shows the running and recurring events of the chat.
+func (app *application) listEvents(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	now := time.Now()
+
+	events, err := app.models.Events.Active(ctx, chatID, now)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	schedules, err := app.models.Events.Schedules(ctx, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	if len(events) == 0 && len(schedules) == 0 {
+		sendMessage(ctx, b, chatID, msgId, ErrNoEvents, true, deleteCmd)
+		return
+	}
+
+	var msg strings.Builder
+	msg.WriteString("🎉 *Events*\n\n")
+
+	for _, event := range events {
+		msg.WriteString(fmt.Sprintf("🔥 *%s* now, ends in %s\n", formatMultiplier(event.Multiplier), humanDuration(event.EndsAt.Sub(now))))
+	}
+
+	for _, schedule := range schedules {
+		status := ""
+		if scheduleActive(schedule, now) {
+			status = " (running)"
+		}
+		msg.WriteString(fmt.Sprintf("📅 `%d` *%s* every %s%s\n", schedule.ID, formatMultiplier(schedule.Multiplier), formatSchedule(schedule), status))
+	}
+
+	sendMessage(ctx, b, chatID, msgId, msg.String(), true, deleteCmd)
+}
+
+// formatSchedule formats when a recurring event runs, e.g. "Friday 20:00-22:00 (UTC)".
+func formatSchedule(schedule database.Schedule) string {
+	return fmt.Sprintf("%s %s-%s (%s)", schedule.Weekday, schedule.StartTime, schedule.EndTime, eventLocation())
+}
+
+// announceEvents posts the end of one-off events and the start and end of
+// recurring events in their chats. The start of one-off events is announced by
+// the /event command itself.
+func (app *application) announceEvents(ctx context.Context, b *bot.Bot, now time.Time) {
+	events, err := app.models.Events.Finished(ctx, now)
+	if err != nil {
+		log.Printf("Failed to fetch finished events: %v\n", err)
+		return
+	}
+
+	for _, event := range events {
+		msg := fmt.Sprintf("🏁 *The %s event has ended.* Points are back to normal.", formatMultiplier(event.Multiplier))
+		sendMessage(ctx, b, event.ChatID, 0, msg, false, false)
+
+		if err := app.models.Events.MarkAnnounced(ctx, event.ID); err != nil {
+			log.Printf("Failed to mark event %d as announced: %v\n", event.ID, err)
+		}
+	}
+
+	schedules, err := app.models.Events.AllSchedules(ctx)
+	if err != nil {
+		log.Printf("Failed to fetch event schedules: %v\n", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		active := scheduleActive(schedule, now)
+		if active == schedule.Active {
+			continue
+		}
+
+		var msg string
+		if active {
+			msg = fmt.Sprintf("🎉 *%s event started!* Every message earns %s points until %s.",
+				formatMultiplier(schedule.Multiplier), formatMultiplier(schedule.Multiplier), schedule.EndTime)
+		} else {
+			msg = fmt.Sprintf("🏁 *The %s event has ended.* See you next %s!", formatMultiplier(schedule.Multiplier), schedule.Weekday)
+		}
+		sendMessage(ctx, b, schedule.ChatID, 0, msg, false, false)
+
+		if err := app.models.Events.SetScheduleActive(ctx, schedule.ID, active); err != nil {
+			log.Printf("Failed to update event schedule %d: %v\n", schedule.ID, err)
+		}
+	}
+}
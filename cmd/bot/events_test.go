@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+func TestParseMultiplier(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"2x", 2, false},
+		{"1.5", 1.5, false},
+		{"10X", 10, false},
+		{"0x", 0, true},
+		{"-2x", 0, true},
+		{"11x", 0, true},
+		{"infx", 0, true},
+		{"+Inf", 0, true},
+		{"NaN", 0, true},
+		{"nanx", 0, true},
+		{"two", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseMultiplier(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseMultiplier(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Weekday
+		wantErr bool
+	}{
+		{"fri", time.Friday, false},
+		{"Friday", time.Friday, false},
+		{"fridays", time.Friday, false},
+		{"SUN", time.Sunday, false},
+		{"thurs", time.Thursday, false},
+		{"fr", 0, true},
+		{"funday", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseWeekday(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseWeekday(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleActive(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	evening := database.Schedule{Weekday: time.Friday, StartTime: "20:00", EndTime: "22:00"}
+	overnight := database.Schedule{Weekday: time.Saturday, StartTime: "23:00", EndTime: "01:00"}
+
+	// 2025-01-03 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		schedule database.Schedule
+		now      time.Time
+		want     bool
+	}{
+		{"before the start", evening, at(3, 19, 59), false},
+		{"at the start", evening, at(3, 20, 0), true},
+		{"before the end", evening, at(3, 21, 59), true},
+		{"at the end", evening, at(3, 22, 0), false},
+		{"other weekday", evening, at(4, 21, 0), false},
+		{"overnight before midnight", overnight, at(4, 23, 30), true},
+		{"overnight after midnight", overnight, at(5, 0, 30), true},
+		{"overnight at the end", overnight, at(5, 1, 0), false},
+		{"overnight morning of the start day", overnight, at(4, 0, 30), false},
+		{"overnight a week later", overnight, at(12, 0, 30), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduleActive(tt.schedule, tt.now); got != tt.want {
+				t.Errorf("scheduleActive(%s-%s, %s) = %v, want %v", tt.schedule.StartTime, tt.schedule.EndTime, tt.now.Format(time.RFC1123), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleActiveInTimezone(t *testing.T) {
+	viper.Set("bot.events.timezone", "Asia/Dhaka")
+	t.Cleanup(viper.Reset)
+
+	// 20:30 in Dhaka (UTC+6) on Friday 2025-01-03 is 14:30 UTC
+	schedule := database.Schedule{Weekday: time.Friday, StartTime: "20:00", EndTime: "22:00"}
+	now := time.Date(2025, time.January, 3, 14, 30, 0, 0, time.UTC)
+	if !scheduleActive(schedule, now) {
+		t.Errorf("scheduleActive() = false at 20:30 in Asia/Dhaka, want true")
+	}
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+// filterUsage explains the /filter arguments.
+const filterUsage = "Usage: `/filter add kind pattern action [value]`, `/filter remove id` or `/filter list`.\n" +
+	"Kinds: `word`, `regex`, `domain` and `invite` (without a pattern).\n" +
+	"Actions: `delete`, `warn`, `mute [duration]` and `deduct amount`.\n" +
+	"(e.g.): `/filter add domain example.com mute 1h` or `/filter add invite warn`"
+
+// Kinds of filters
+const (
+	filterWord   = "word"   // A whole word, in any case
+	filterRegex  = "regex"  // A regular expression
+	filterInvite = "invite" // Any Telegram invite link
+	filterDomain = "domain" // Links to a domain or its subdomains
+)
+
+// Actions of filters. Matching messages are deleted whatever the action.
+const (
+	filterDelete = "delete" // Only delete the message
+	filterWarn   = "warn"   // Also warn the author
+	filterMute   = "mute"   // Also mute the author
+	filterDeduct = "deduct" // Also deduct points from the author
+)
+
+var (
+	// bareLinkPattern finds links with or without a scheme, so bare domains like
+	// example.com are caught too, unlike linkPattern.
+	bareLinkPattern = regexp.MustCompile(`(?i)(?:[a-z][a-z0-9+.-]*://)?(?:[a-z0-9-]+\.)+[a-z]{2,}(?:[/?#][^\s]*)?`)
+
+	// invitePattern finds Telegram invite links.
+	invitePattern = regexp.MustCompile(`(?i)(?:t\.me|telegram\.(?:me|dog))/(?:\+|joinchat/)[\w-]+|tg://join\?invite=`)
+)
+
+// filter manages the blocklist of the chat.
+// (e.g.): "/filter add word spam delete" || "/filter list" || "/filter remove 3"
+func (app *application) filter(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	parts := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/filter", "", 1)))
+	if len(parts) == 0 {
+		parts = []string{"list"}
+	}
+
+	switch {
+	case parts[0] == "list" && len(parts) == 1:
+		filters, err := app.models.Filters.List(ctx, chatID)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+		if len(filters) == 0 {
+			sendMessage(ctx, b, chatID, msgId, ErrNoFilters, true, deleteCmd)
+			return
+		}
+
+		var msg strings.Builder
+		msg.WriteString("🚫 *Filters*\n\n")
+		for _, filter := range filters {
+			msg.WriteString(fmt.Sprintf("`%d` - %s\n", filter.ID, formatFilter(filter)))
+		}
+		sendMessage(ctx, b, chatID, msgId, msg.String(), true, deleteCmd)
+
+	case parts[0] == "add" && len(parts) >= 3:
+		filter, err := parseFilter(parts[1:])
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, err.Error()+"\n\n"+filterUsage, true, deleteCmd)
+			return
+		}
+		filter.ChatID = chatID
+		filter.CreatedBy = update.Message.From.ID
+
+		err = app.models.Filters.Insert(ctx, filter)
+		if err != nil {
+			log.Printf("Failed to add filter: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+
+		log.Printf("Filter %d added in chat %d by %d: %s %q %s\n", filter.ID, chatID, filter.CreatedBy, filter.Kind, filter.Pattern, filter.Action)
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:  "filter add",
+			ActorID: update.Message.From.ID,
+			Actor:   update.Message.From,
+			Details: fmt.Sprintf("Filter %d: %s", filter.ID, formatFilter(*filter)),
+		})
+		sendMessage(ctx, b, chatID, msgId, fmt.Sprintf("✅ Filter `%d` added: %s", filter.ID, formatFilter(*filter)), true, deleteCmd)
+
+	case parts[0] == "remove" && len(parts) == 2:
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrFilterNotFound, true, deleteCmd)
+			return
+		}
+
+		removed, err := app.models.Filters.Delete(ctx, chatID, id)
+		if err != nil {
+			log.Printf("Failed to remove filter: %v\n", err)
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+		if !removed {
+			sendMessage(ctx, b, chatID, msgId, ErrFilterNotFound, true, deleteCmd)
+			return
+		}
+
+		log.Printf("Filter %d removed in chat %d by %d\n", id, chatID, update.Message.From.ID)
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:  "filter remove",
+			ActorID: update.Message.From.ID,
+			Actor:   update.Message.From,
+			Details: fmt.Sprintf("Filter %d", id),
+		})
+		sendMessage(ctx, b, chatID, msgId, fmt.Sprintf("🗑 Filter `%d` removed.", id), true, deleteCmd)
+
+	default:
+		sendMessage(ctx, b, chatID, msgId, filterUsage, true, deleteCmd)
+	}
+}
+
+// parseFilter parses the arguments of "/filter add": a kind, a pattern unless
+// the kind is invite, an action and the value of the action if it takes one.
+func parseFilter(args []string) (*database.Filter, error) {
+	filter := &database.Filter{Kind: strings.ToLower(args[0])}
+	args = args[1:]
+
+	switch filter.Kind {
+	case filterInvite:
+	case filterWord, filterRegex, filterDomain:
+		if len(args) == 0 {
+			return nil, fmt.Errorf("The %s filter needs a pattern.", filter.Kind)
+		}
+		filter.Pattern, args = args[0], args[1:]
+	default:
+		return nil, errors.New("Unknown filter kind.")
+	}
+
+	switch filter.Kind {
+	case filterRegex:
+		if _, err := regexp.Compile(filter.Pattern); err != nil {
+			return nil, errors.New("The regular expression is invalid.")
+		}
+	case filterDomain:
+		filter.Pattern = strings.ToLower(strings.TrimPrefix(filter.Pattern, "*."))
+	}
+
+	if len(args) == 0 {
+		return nil, errors.New("The filter needs an action.")
+	}
+	filter.Action, args = strings.ToLower(args[0]), args[1:]
+
+	switch {
+	case (filter.Action == filterDelete || filter.Action == filterWarn || filter.Action == filterMute) && len(args) == 0:
+	case filter.Action == filterMute && len(args) == 1:
+		if d, err := parseDuration(args[0]); err != nil || d <= 0 || checkSanctionDuration(d) != nil {
+			return nil, errors.New("The mute duration must be like `30m`, `1h` or `2d`, between `30s` and `366d`.")
+		}
+		filter.Duration = args[0]
+	case filter.Action == filterDeduct && len(args) == 1:
+		amount, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || amount <= 0 {
+			return nil, errors.New(ErrInvalidPointsAmount)
+		}
+		filter.Amount = amount
+	default:
+		return nil, errors.New("Unknown action or wrong number of arguments.")
+	}
+
+	return filter, nil
+}
+
+// formatFilter describes a filter, e.g. "domain `example.com` → mute 1h".
+func formatFilter(filter database.Filter) string {
+	target := filter.Kind
+	if filter.Pattern != "" {
+		target += fmt.Sprintf(" `%s`", filter.Pattern)
+	}
+
+	action := filter.Action
+	switch filter.Action {
+	case filterMute:
+		if filter.Duration != "" {
+			action += " " + filter.Duration
+		}
+	case filterDeduct:
+		action += fmt.Sprintf(" %.2f points", filter.Amount)
+	}
+
+	return fmt.Sprintf("%s → %s", target, action)
+}
+
+// matchFilter returns the first filter the message matches, or nil.
+func matchFilter(filters []database.Filter, msg *models.Message) *database.Filter {
+	text := msg.Text
+	if text == "" {
+		text = msg.Caption
+	}
+	links := messageLinks(msg)
+
+	for i, filter := range filters {
+		var matched bool
+		switch filter.Kind {
+		case filterWord:
+			re, err := regexp.Compile(`(?i)(^|[^\p{L}\p{N}_])` + regexp.QuoteMeta(filter.Pattern) + `($|[^\p{L}\p{N}_])`)
+			matched = err == nil && re.MatchString(text)
+		case filterRegex:
+			re, err := regexp.Compile(filter.Pattern)
+			matched = err == nil && re.MatchString(text)
+		case filterInvite:
+			for _, link := range links {
+				matched = matched || invitePattern.MatchString(link)
+			}
+		case filterDomain:
+			for _, link := range links {
+				host := linkHost(link)
+				matched = matched || host == filter.Pattern || strings.HasSuffix(host, "."+filter.Pattern)
+			}
+		}
+
+		if matched {
+			return &filters[i]
+		}
+	}
+
+	return nil
+}
+
+// messageLinks returns the links in the text or caption of a message,
+// including the targets of text links.
+func messageLinks(msg *models.Message) []string {
+	links := bareLinkPattern.FindAllString(msg.Text+"\n"+msg.Caption, -1)
+
+	for _, entity := range slices.Concat(msg.Entities, msg.CaptionEntities) {
+		if entity.Type == models.MessageEntityTypeTextLink {
+			links = append(links, entity.URL)
+		}
+	}
+
+	return links
+}
+
+// linkHost returns the lowercase host name of a link, which may lack a scheme.
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// filterMiddleware deletes messages that match a filter of the chat and
+// applies the action of the filter to their author. It runs on every update,
+// so commands and edited messages are filtered too. Filtered messages don't
+// earn points, and messages edited into blocked content lose what they earned.
+// Admins, bots and messages sent on behalf of chats are never filtered.
+func (app *application) filterMiddleware(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		msg, edited := update.Message, false
+		if msg == nil && update.EditedMessage != nil {
+			msg, edited = update.EditedMessage, true
+		}
+		if msg == nil || !isGroupChat(msg.Chat) || msg.SenderChat != nil || msg.From == nil || msg.From.IsBot {
+			next(ctx, b, update)
+			return
+		}
+
+		filters, err := app.models.Filters.List(ctx, msg.Chat.ID)
+		if err != nil {
+			log.Printf("Failed to fetch filters: %v\n", err)
+			next(ctx, b, update)
+			return
+		}
+
+		filter := matchFilter(filters, msg)
+		if filter == nil {
+			next(ctx, b, update)
+			return
+		}
+
+		admins, err := getAdmins(ctx, b, msg.Chat.ID)
+		if err != nil {
+			log.Printf("Failed to retrieve admins: %v\n", err)
+			next(ctx, b, update)
+			return
+		}
+
+		if isAdmin(msg.From.ID, admins) {
+			next(ctx, b, update)
+			return
+		}
+
+		app.applyFilter(ctx, b, msg, filter)
+
+		if edited {
+			record, err := app.models.Messages.Get(ctx, msg.Chat.ID, msg.ID)
+			if err != nil {
+				log.Printf("Failed to fetch message %d: %v\n", msg.ID, err)
+				return
+			}
+			if record != nil {
+				app.revokeMessagePoints(ctx, msg.Chat.ID, []database.Message{*record}, pointSources[9])
+			}
+		}
+	}
+}
+
+// applyFilter deletes a filtered message and applies the action of the filter.
+func (app *application) applyFilter(ctx context.Context, b *bot.Bot, msg *models.Message, filter *database.Filter) {
+	chatID := msg.Chat.ID
+	userID := msg.From.ID
+
+	_, err := b.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: chatID, MessageID: msg.ID})
+	if err != nil {
+		log.Printf("Failed to delete filtered message %d: %v\n", msg.ID, err)
+	}
+
+	log.Printf("Message %d of user %d in chat %d matched filter %d: %s\n", msg.ID, userID, chatID, filter.ID, filter.Action)
+
+	name := mention(userID, msg.From)
+	reason := fmt.Sprintf("blocked %s", filter.Kind)
+
+	record := adminAction{
+		Action:   filter.Action,
+		TargetID: userID,
+		Target:   msg.From,
+		Reason:   reason,
+		Details:  fmt.Sprintf("Filter %d: %s", filter.ID, formatFilter(*filter)),
+	}
+
+	var notice string
+	switch filter.Action {
+	case filterWarn:
+		notice, err = app.addWarn(ctx, b, chatID, userID, filter.CreatedBy, reason, msg.From)
+		if err != nil {
+			log.Printf("Failed to warn user: %v\n", err)
+			return
+		}
+	case filterMute:
+		d, _ := parseDuration(filter.Duration)
+		if err := applySanction(ctx, b, chatID, userID, sanctionMute, d); err != nil {
+			log.Printf("Failed to mute user %d in chat %d: %v\n", userID, chatID, err)
+			return
+		}
+		notice = fmt.Sprintf("%s: %s (%s).", formatSanction(sanctionMute, d), name, reason)
+		record.Duration = d
+	case filterDeduct:
+		removed, err := app.debit(ctx, chatID, userID, filter.Amount, pointSources[6])
+		if err != nil {
+			log.Printf("Failed to deduct filter penalty: %v\n", err)
+			return
+		}
+		if removed > 0 {
+			notice = fmt.Sprintf("➖ %s lost %.2f points (%s).", name, removed, reason)
+		}
+		record.Amount = removed
+	}
+
+	app.logAction(ctx, b, chatID, record)
+
+	if notice == "" {
+		return
+	}
+
+	if _, err := sendNotice(ctx, b, chatID, notice, nil); err != nil {
+		log.Printf("Failed to send filter notice: %v\n", err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+)
+
+func TestMatchFilter(t *testing.T) {
+	word := database.Filter{ID: 1, Kind: filterWord, Pattern: "spam"}
+	regex := database.Filter{ID: 2, Kind: filterRegex, Pattern: `(?i)fre+ coins`}
+	invite := database.Filter{ID: 3, Kind: filterInvite}
+	domain := database.Filter{ID: 4, Kind: filterDomain, Pattern: "example.com"}
+
+	tests := []struct {
+		name   string
+		filter database.Filter
+		msg    models.Message
+		want   bool
+	}{
+		{"word", word, models.Message{Text: "this is spam"}, true},
+		{"word in any case", word, models.Message{Text: "SPAM!"}, true},
+		{"word inside another word", word, models.Message{Text: "spammer here"}, false},
+		{"word after underscore", word, models.Message{Text: "no_spam"}, false},
+		{"word next to punctuation", word, models.Message{Text: "(spam)"}, true},
+		{"word in caption", word, models.Message{Caption: "spam photo"}, true},
+		{"regex", regex, models.Message{Text: "get FREEE coins now"}, true},
+		{"regex no match", regex, models.Message{Text: "free stuff"}, false},
+		{"invite link", invite, models.Message{Text: "join t.me/+AbCdEf123"}, true},
+		{"joinchat link", invite, models.Message{Text: "https://telegram.me/joinchat/AbC-dEf"}, true},
+		{"public channel is no invite", invite, models.Message{Text: "t.me/somechannel"}, false},
+		{"invite as text link", invite, models.Message{
+			Text:     "click here",
+			Entities: []models.MessageEntity{{Type: models.MessageEntityTypeTextLink, URL: "https://t.me/+secret"}},
+		}, true},
+		{"tg link as text link", invite, models.Message{
+			Text:     "open",
+			Entities: []models.MessageEntity{{Type: models.MessageEntityTypeTextLink, URL: "tg://join?invite=AbC"}},
+		}, true},
+		{"domain", domain, models.Message{Text: "see https://example.com/page"}, true},
+		{"domain without scheme", domain, models.Message{Text: "see example.com"}, true},
+		{"subdomain", domain, models.Message{Text: "see www.shop.Example.com/x"}, true},
+		{"lookalike domain", domain, models.Message{Text: "see notexample.com"}, false},
+		{"domain as a prefix", domain, models.Message{Text: "see example.com.evil.org"}, false},
+		{"domain in text link", domain, models.Message{
+			Text:     "harmless",
+			Entities: []models.MessageEntity{{Type: models.MessageEntityTypeTextLink, URL: "http://sub.example.com"}},
+		}, true},
+		{"domain not linked", domain, models.Message{Text: "example dot com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchFilter([]database.Filter{tt.filter}, &tt.msg)
+			if (got != nil) != tt.want {
+				t.Errorf("matchFilter(%q) = %v, want match %v", tt.msg.Text+tt.msg.Caption, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFilterReturnsFirstMatch(t *testing.T) {
+	filters := []database.Filter{
+		{ID: 1, Kind: filterWord, Pattern: "other"},
+		{ID: 2, Kind: filterWord, Pattern: "spam"},
+		{ID: 3, Kind: filterRegex, Pattern: "spam"},
+	}
+
+	got := matchFilter(filters, &models.Message{Text: "spam"})
+	if got == nil || got.ID != 2 {
+		t.Errorf("matchFilter() = %+v, want filter 2", got)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    database.Filter
+		wantErr bool
+	}{
+		{"word delete", []string{"word", "spam", "delete"}, database.Filter{Kind: filterWord, Pattern: "spam", Action: filterDelete}, false},
+		{"kind and action ignore case", []string{"WORD", "spam", "Warn"}, database.Filter{Kind: filterWord, Pattern: "spam", Action: filterWarn}, false},
+		{"invite needs no pattern", []string{"invite", "deduct", "10"}, database.Filter{Kind: filterInvite, Action: filterDeduct, Amount: 10}, false},
+		{"domain wildcard", []string{"domain", "*.Example.com", "mute", "1h"}, database.Filter{Kind: filterDomain, Pattern: "example.com", Action: filterMute, Duration: "1h"}, false},
+		{"mute forever", []string{"regex", "fre+", "mute"}, database.Filter{Kind: filterRegex, Pattern: "fre+", Action: filterMute}, false},
+		{"unknown kind", []string{"phrase", "spam", "delete"}, database.Filter{}, true},
+		{"missing pattern", []string{"word"}, database.Filter{}, true},
+		{"missing action", []string{"word", "spam"}, database.Filter{}, true},
+		{"invalid regex", []string{"regex", "(", "delete"}, database.Filter{}, true},
+		{"mute too short", []string{"word", "spam", "mute", "10s"}, database.Filter{}, true},
+		{"deduct without amount", []string{"word", "spam", "deduct"}, database.Filter{}, true},
+		{"negative deduct", []string{"word", "spam", "deduct", "-5"}, database.Filter{}, true},
+		{"delete with extra argument", []string{"word", "spam", "delete", "1h"}, database.Filter{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("parseFilter(%q) = %+v, want %+v", tt.args, *got, tt.want)
+			}
+		})
+	}
+}
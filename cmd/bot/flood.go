@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+// unmuteButton is the callback data prefix of the unmute button under flood notices.
+const unmuteButton = "unmute:"
+
+// floodMessage is a message counted by the flood guard.
+type floodMessage struct {
+	id int
+	at time.Time
+}
+
+// floodGuard remembers the latest messages of every user to catch floods.
+// Like the throttle, the state is short-lived and kept in memory.
+type floodGuard struct {
+	mu        sync.Mutex
+	users     map[chatUser][]floodMessage
+	lastSweep time.Time
+}
+
+func newFloodGuard() *floodGuard {
+	return &floodGuard{
+		users:     make(map[chatUser][]floodMessage),
+		lastSweep: time.Now(),
+	}
+}
+
+// track records a message sent by the user at now. When the user sent more
+// than bot.flood.messages messages within bot.flood.seconds, it reports a flood
+// along with the IDs of the messages in it, oldest first, and starts counting
+// from scratch.
+func (f *floodGuard) track(chatID, userID int64, messageID int, now time.Time) ([]int, bool) {
+	limit := viper.GetInt("bot.flood.messages")
+	window := time.Duration(viper.GetInt("bot.flood.seconds")) * time.Second
+	if limit <= 0 || window <= 0 {
+		return nil, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sweep(now, window)
+
+	key := chatUser{chatID: chatID, userID: userID}
+
+	// Only keep the messages that are still within the window
+	recent := f.users[key]
+	for len(recent) > 0 && now.Sub(recent[0].at) > window {
+		recent = recent[1:]
+	}
+	recent = append(recent, floodMessage{id: messageID, at: now})
+
+	if len(recent) <= limit {
+		f.users[key] = recent
+		return nil, false
+	}
+
+	delete(f.users, key)
+
+	ids := make([]int, len(recent))
+	for i, msg := range recent {
+		ids[i] = msg.id
+	}
+	return ids, true
+}
+
+// sweep drops users whose latest message is older than the window.
+// It runs at most once every throttleSweepInterval and must be called with f.mu held.
+func (f *floodGuard) sweep(now time.Time, window time.Duration) {
+	if now.Sub(f.lastSweep) < throttleSweepInterval {
+		return
+	}
+	f.lastSweep = now
+
+	for key, recent := range f.users {
+		if now.Sub(recent[len(recent)-1].at) > window {
+			delete(f.users, key)
+		}
+	}
+}
+
+// validateFloodConfig reports invalid bot.flood settings.
+func validateFloodConfig() error {
+	if viper.GetInt("bot.flood.messages") < 0 || viper.GetInt("bot.flood.seconds") < 0 {
+		return fmt.Errorf("bot.flood.messages and bot.flood.seconds must not be negative")
+	}
+
+	if mute := viper.GetString("bot.flood.mute"); mute != "" {
+		d, err := parseDuration(mute)
+		if err == nil {
+			err = checkSanctionDuration(d)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid bot.flood.mute: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// floodMiddleware stops members who flood the chat before their messages are
+// counted. It runs on every update, so repeated commands count as well.
+// Admins, bots and messages sent on behalf of chats are never stopped.
+func (app *application) floodMiddleware(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		msg := update.Message
+		if msg == nil || !isGroupChat(msg.Chat) || msg.SenderChat != nil || msg.From == nil || msg.From.IsBot || len(msg.NewChatMembers) != 0 {
+			next(ctx, b, update)
+			return
+		}
+
+		burst, flooded := app.flood.track(msg.Chat.ID, msg.From.ID, msg.ID, time.Now())
+		if !flooded {
+			next(ctx, b, update)
+			return
+		}
+
+		admins, err := getAdmins(ctx, b, msg.Chat.ID)
+		if err != nil {
+			log.Printf("Failed to retrieve admins: %v\n", err)
+			next(ctx, b, update)
+			return
+		}
+
+		if isAdmin(msg.From.ID, admins) {
+			next(ctx, b, update)
+			return
+		}
+
+		// The message that tipped the flood is never counted
+		app.stopFlood(ctx, b, msg, burst)
+	}
+}
+
+// stopFlood mutes the author of a flood for bot.flood.mute, deletes the flood
+// if bot.flood.deleteMessages is set, takes back the points it earned if
+// bot.flood.revokePoints is set, and posts a notice with an unmute button for admins.
+func (app *application) stopFlood(ctx context.Context, b *bot.Bot, msg *models.Message, burst []int) {
+	chatID := msg.Chat.ID
+	userID := msg.From.ID
+
+	perm, err := botRights(ctx, b, chatID)
+	if err != nil {
+		log.Printf("Failed to check bot rights: %v\n", err)
+		return
+	}
+
+	var notice strings.Builder
+	notice.WriteString(fmt.Sprintf("🌊 %s sent %d messages within %d seconds.\n", mention(userID, msg.From), len(burst), viper.GetInt("bot.flood.seconds")))
+
+	muted := false
+	d, _ := parseDuration(viper.GetString("bot.flood.mute"))
+	if perm != nil && perm.CanRestrictMembers {
+		if err := applySanction(ctx, b, chatID, userID, sanctionMute, d); err != nil {
+			log.Printf("Failed to mute flooding user %d in chat %d: %v\n", userID, chatID, err)
+		} else {
+			muted = true
+			notice.WriteString(formatSanction(sanctionMute, d) + ".\n")
+		}
+	}
+	if !muted {
+		notice.WriteString("❗ Could not mute the user, check my admin rights.\n")
+	}
+
+	var revoked float64
+	if viper.GetBool("bot.flood.revokePoints") {
+		records, err := app.models.Messages.Range(ctx, chatID, burst[0], burst[len(burst)-1])
+		if err != nil {
+			log.Printf("Failed to fetch flood messages: %v\n", err)
+		}
+
+		// Other members may have written in between
+		var own []database.Message
+		for _, record := range records {
+			if record.UserID == userID {
+				own = append(own, record)
+			}
+		}
+
+		revoked = app.revokeMessagePoints(ctx, chatID, own, pointSources[12])
+		if revoked > 0 {
+			notice.WriteString(fmt.Sprintf("➖ %.2f points earned during the flood were revoked.\n", revoked))
+		}
+	}
+
+	if viper.GetBool("bot.flood.deleteMessages") && perm != nil && perm.CanDeleteMessages {
+		deleteMessages(ctx, b, chatID, burst)
+	}
+
+	log.Printf("User %d flooded chat %d with messages %d-%d\n", userID, chatID, burst[0], burst[len(burst)-1])
+	if muted {
+		app.logAction(ctx, b, chatID, adminAction{
+			Action:   sanctionMute,
+			TargetID: userID,
+			Target:   msg.From,
+			Duration: d,
+			Amount:   revoked,
+			Reason:   "flood",
+			Details:  fmt.Sprintf("%d messages within %d seconds", len(burst), viper.GetInt("bot.flood.seconds")),
+		})
+	}
+
+	var markup models.ReplyMarkup
+	if muted {
+		markup = &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: "🔊 Unmute", CallbackData: unmuteButton + strconv.FormatInt(userID, 10)},
+			}},
+		}
+	}
+
+	if _, err := sendNotice(ctx, b, chatID, notice.String(), markup); err != nil {
+		log.Printf("Failed to send flood notice: %v\n", err)
+	}
+}
+
+// unmuteFlood lifts a flood mute when an admin presses the unmute button.
+func (app *application) unmuteFlood(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	notice := query.Message.Message
+	chatID := notice.Chat.ID
+
+	userID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, unmuteButton), 10, 64)
+	if err != nil {
+		answerCallback(ctx, b, query, "Unknown user.")
+		return
+	}
+
+	err = unmuteMember(ctx, b, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to unmute user %d in chat %d: %v\n", userID, chatID, err)
+		answerCallback(ctx, b, query, "Could not unmute the user, check my admin rights.")
+		return
+	}
+
+	log.Printf("User %d in chat %d unmuted by %d\n", userID, chatID, query.From.ID)
+
+	var user *models.User
+	if member := chatMember(ctx, b, chatID, userID); member != nil {
+		user = memberUser(*member)
+	}
+
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "unmute",
+		ActorID:  query.From.ID,
+		Actor:    &query.From,
+		TargetID: userID,
+		Target:   user,
+		Reason:   "flood",
+	})
+
+	// Replace the notice so the button can't be pressed twice
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: notice.ID,
+		Text:      fmt.Sprintf("🔊 %s was unmuted by %s.", mention(userID, user), mention(query.From.ID, &query.From)),
+		ParseMode: models.ParseModeMarkdownV1,
+	})
+	if err != nil {
+		log.Printf("Failed to update flood notice: %v\n", err)
+	}
+
+	answerCallback(ctx, b, query, "User unmuted.")
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/spf13/viper"
+)
+
+// greetingsUsage explains the /greetings arguments.
+const greetingsUsage = "Usage: `/greetings on`, `/greetings off`, `/greetings delete seconds` or `/greetings rules link`."
+
+// greetingPlaceholders explains what templates can contain.
+const greetingPlaceholders = "Placeholders: `{name}` mentions the member, `{count}` is the number of members, `{chat}` is the chat title and `{rules}` the rules link."
+
+// greetings are the greeting settings of a chat, with the config file filling
+// in what admins never changed.
+type greetings struct {
+	welcome     string
+	goodbye     string
+	enabled     bool
+	deleteAfter time.Duration
+	rulesLink   string
+}
+
+// greetingSettings returns the greeting settings of a chat. Settings that were
+// never changed with /setwelcome, /setgoodbye or /greetings fall back to bot.greetings.
+func (app *application) greetingSettings(ctx context.Context, chatID int64) (greetings, error) {
+	g := greetings{
+		welcome:     viper.GetString("bot.greetings.welcome"),
+		goodbye:     viper.GetString("bot.greetings.goodbye"),
+		enabled:     viper.GetBool("bot.greetings.enabled"),
+		deleteAfter: time.Duration(viper.GetInt("bot.greetings.deleteAfter")) * time.Second,
+		rulesLink:   viper.GetString("bot.greetings.rules"),
+	}
+
+	settings, err := app.models.Settings.Get(ctx, chatID)
+	if err != nil || settings == nil {
+		return g, err
+	}
+
+	s := settings.Greetings
+	if s.Welcome != nil {
+		g.welcome = *s.Welcome
+	}
+	if s.Goodbye != nil {
+		g.goodbye = *s.Goodbye
+	}
+	if s.Enabled != nil {
+		g.enabled = *s.Enabled
+	}
+	if s.DeleteAfter != nil {
+		g.deleteAfter = time.Duration(*s.DeleteAfter) * time.Second
+	}
+	if s.RulesLink != nil {
+		g.rulesLink = *s.RulesLink
+	}
+
+	return g, nil
+}
+
+// renderGreeting fills in the placeholders of a greeting template.
+func renderGreeting(ctx context.Context, b *bot.Bot, template string, chat models.Chat, user *models.User, rulesLink string) string {
+	count := "?"
+	if n, err := b.GetChatMemberCount(ctx, &bot.GetChatMemberCountParams{ChatID: chat.ID}); err == nil {
+		count = strconv.Itoa(n)
+	}
+
+	return strings.NewReplacer(
+		"{name}", mention(user.ID, user),
+		"{count}", count,
+		"{chat}", escapeMarkdown(chat.Title),
+		"{rules}", rulesLink,
+	).Replace(template)
+}
+
+// greet sends the welcome or goodbye template of a chat for user, if greetings
+// are enabled and the template is set, and deletes it after the configured time.
+func (app *application) greet(ctx context.Context, b *bot.Bot, chat models.Chat, user *models.User, welcome bool) {
+	g, err := app.greetingSettings(ctx, chat.ID)
+	if err != nil {
+		log.Printf("Failed to fetch greeting settings: %v\n", err)
+		return
+	}
+
+	template := g.goodbye
+	if welcome {
+		template = g.welcome
+	}
+	if !g.enabled || template == "" {
+		return
+	}
+
+	msg, err := sendNotice(ctx, b, chat.ID, renderGreeting(ctx, b, template, chat, user, g.rulesLink), nil)
+	if err != nil {
+		log.Printf("Failed to send greeting in chat %d: %v\n", chat.ID, err)
+		return
+	}
+
+	if g.deleteAfter > 0 {
+		time.AfterFunc(g.deleteAfter, func() {
+			b.DeleteMessage(context.Background(), &bot.DeleteMessageParams{ChatID: chat.ID, MessageID: msg.ID})
+		})
+	}
+}
+
+// setWelcome sets the template greeting members who join the chat.
+// (e.g.): "/setwelcome Welcome {name}! Please read {rules}"
+func (app *application) setWelcome(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.setGreeting(ctx, b, update, "/setwelcome", app.models.Settings.SetWelcome)
+}
+
+// setGoodbye sets the template sent when members leave the chat.
+// (e.g.): "/setgoodbye Goodbye {name}!"
+func (app *application) setGoodbye(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.setGreeting(ctx, b, update, "/setgoodbye", app.models.Settings.SetGoodbye)
+}
+
+// setGreeting stores the template following command with set. Without a
+// template it shows the current one. The template is previewed before it is
+// stored, so templates Telegram can't render are rejected.
+func (app *application) setGreeting(ctx context.Context, b *bot.Bot, update *models.Update, command string,
+	set func(ctx context.Context, chatID int64, text string) error) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	template := strings.TrimSpace(strings.Replace(update.Message.Text, command, "", 1))
+
+	g, err := app.greetingSettings(ctx, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	if template == "" {
+		current := g.goodbye
+		if command == "/setwelcome" {
+			current = g.welcome
+		}
+		if current == "" {
+			current = "(none)"
+		}
+
+		msg := fmt.Sprintf("Usage: `%s template`\n%s\n\nCurrent template:\n%s", command, greetingPlaceholders, current)
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	preview := "👀 *Preview:*\n\n" + renderGreeting(ctx, b, template, update.Message.Chat, update.Message.From, g.rulesLink)
+	if _, err := sendNotice(ctx, b, chatID, preview, nil); err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrInvalidTemplate, true, deleteCmd)
+		return
+	}
+
+	err = set(ctx, chatID, template)
+	if err != nil {
+		log.Printf("Failed to set greeting: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("Greeting %s set in chat %d by %d\n", command, chatID, update.Message.From.ID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  strings.TrimPrefix(command, "/"),
+		ActorID: update.Message.From.ID,
+		Actor:   update.Message.From,
+		Details: template,
+	})
+
+	msg := "✅ Template saved."
+	if !g.enabled {
+		msg += " Greetings are off, turn them on with `/greetings on`."
+	}
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
+
+// greetingsSettings shows or changes whether greetings are sent, when they are
+// deleted and the rules link they can point to.
+// (e.g.): "/greetings on" || "/greetings delete 60" || "/greetings rules https://t.me/c/1/2"
+func (app *application) greetingsSettings(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+	settings := app.models.Settings
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	parts := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/greetings", "", 1)))
+
+	var err error
+	switch {
+	case len(parts) == 0:
+		g, err := app.greetingSettings(ctx, chatID)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+		sendMessage(ctx, b, chatID, msgId, formatGreetings(g), true, deleteCmd)
+		return
+	case parts[0] == "on" && len(parts) == 1:
+		err = settings.SetGreetingsEnabled(ctx, chatID, true)
+	case parts[0] == "off" && len(parts) == 1:
+		err = settings.SetGreetingsEnabled(ctx, chatID, false)
+	case parts[0] == "delete" && len(parts) == 2:
+		seconds, convErr := strconv.Atoi(parts[1])
+		if convErr != nil || seconds < 0 {
+			sendMessage(ctx, b, chatID, msgId, greetingsUsage, true, deleteCmd)
+			return
+		}
+		err = settings.SetGreetingsDeleteAfter(ctx, chatID, seconds)
+	case parts[0] == "rules" && len(parts) == 2:
+		err = settings.SetRulesLink(ctx, chatID, parts[1])
+	default:
+		sendMessage(ctx, b, chatID, msgId, greetingsUsage, true, deleteCmd)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to change greetings: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("Greetings changed in chat %d by %d: %s\n", chatID, update.Message.From.ID, strings.Join(parts, " "))
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  "greetings",
+		ActorID: update.Message.From.ID,
+		Actor:   update.Message.From,
+		Details: strings.Join(parts, " "),
+	})
+
+	g, err := app.greetingSettings(ctx, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+	sendMessage(ctx, b, chatID, msgId, formatGreetings(g), true, deleteCmd)
+}
+
+// formatGreetings describes the greeting settings of a chat.
+func formatGreetings(g greetings) string {
+	var msg strings.Builder
+
+	if g.enabled {
+		msg.WriteString("👋 Greetings are *on*.\n")
+	} else {
+		msg.WriteString("👋 Greetings are *off*.\n")
+	}
+
+	if g.deleteAfter > 0 {
+		msg.WriteString(fmt.Sprintf("🗑 They are deleted after %.0f seconds.\n", g.deleteAfter.Seconds()))
+	}
+	if g.welcome == "" {
+		msg.WriteString("No welcome template, set one with `/setwelcome`.\n")
+	}
+	if g.goodbye == "" {
+		msg.WriteString("No goodbye template, set one with `/setgoodbye`.\n")
+	}
+	if g.rulesLink != "" {
+		msg.WriteString(fmt.Sprintf("📜 Rules: `%s`\n", g.rulesLink))
+	}
+
+	return msg.String()
+}
@@ -3,12 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand/v2"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
 )
 
 // sendMessage is a helper function for sending messages in a Telegram chat.
@@ -22,6 +26,8 @@ import (
 // - reply: If true, the message will be sent as a reply to messageID.
 // - delete: If true, the original message (messageID) will be deleted after sending.
 //
+// In forums the message is sent to the topic the handled update came from.
+//
 // This function allows flexibility in message handling by providing options
 // to reply to a specific message and delete the user's original command/message.
 func sendMessage(ctx context.Context, b *bot.Bot, chatID int64, messageID int, text string, reply, delete bool) {
@@ -33,6 +39,11 @@ func sendMessage(ctx context.Context, b *bot.Bot, chatID int64, messageID int, t
 		ParseMode: models.ParseModeMarkdownV1,
 	}
 
+	// Keep the conversation in the topic it started in
+	if thread, ok := ctx.Value(topicKey{}).(int); ok {
+		msgParams.MessageThreadID = thread
+	}
+
 	// If reply is enabled, attach reply parameters
 	if reply {
 		msgParams.ReplyParameters = &models.ReplyParameters{
@@ -52,6 +63,31 @@ func sendMessage(ctx context.Context, b *bot.Bot, chatID int64, messageID int, t
 	}
 }
 
+// sendNotice sends text with the given inline buttons to a chat, in the forum
+// topic of the handled update, and returns the sent message.
+func sendNotice(ctx context.Context, b *bot.Bot, chatID int64, text string, markup models.ReplyMarkup) (*models.Message, error) {
+	params := &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeMarkdownV1,
+		ReplyMarkup: markup,
+	}
+
+	if thread, ok := ctx.Value(topicKey{}).(int); ok {
+		params.MessageThreadID = thread
+	}
+
+	return b.SendMessage(ctx, params)
+}
+
+// answerCallback acknowledges a button press, showing text to the user who pressed it.
+func answerCallback(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, text string) {
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            text,
+	})
+}
+
 // randRange generates a random integer between min (inclusive) and max (exclusive).
 func randRange(min, max int) int {
 	return rand.IntN(max-min) + min
@@ -73,34 +109,58 @@ func displayName(firstName, username string) string {
 }
 
 // formatUserDetails formats user details using Markdown
-func formatUserDetails(firstName, username string, user *database.User) string {
+// remainingToday is only shown when a daily earning cap is configured (i.e. not +Inf).
+func formatUserDetails(firstName, username string, user *database.User, remainingToday float64) string {
 	var sb strings.Builder
 
 	// Format the message
 	sb.WriteString(fmt.Sprintf("**Stats of %s**\n", displayName(firstName, username)))
 	sb.WriteString(fmt.Sprintf("- **Total Points:** `%.2f`\n", user.Points))
+	if !math.IsInf(remainingToday, 1) {
+		sb.WriteString(fmt.Sprintf("- **Remaining Today:** `%.2f`\n", remainingToday))
+	}
 	sb.WriteString(fmt.Sprintf("- **Last Activity:** `%s`\n", user.UpdatedAt.Format("2006-01-02 15:04:05")))
 
 	return sb.String()
 }
 
 // Function to calculate points based on message type
-func calculatePoints(msg *models.Message) float64 {
+// Text messages earn random points scored against the user's recent messages
+// (see scoreText), every other kind earns bot.point.<kind>. Captions and
+// forwards are then adjusted by bot.point.caption and bot.point.forwarded.
+// When random is false text earns the middle of its range instead, so scoring
+// the same text again always gives the same result.
+func calculatePoints(msg *models.Message, recent []string, random bool) float64 {
+	kind := kindOf(msg)
+	if kind == nil {
+		return 0
+	}
+
 	var point float64
 
-	switch {
-	case msg.Text != "":
-		point = pointMap["text"]()
-	case msg.Document != nil:
-		point = pointMap["document"]()
-	case msg.Photo != nil:
-		point = pointMap["photo"]()
-	case msg.Sticker != nil:
-		point = pointMap["sticker"]()
-	case msg.Audio != nil:
-		point = pointMap["audio"]()
-	case msg.Animation != nil:
-		point = pointMap["animation"]()
+	switch kind.key {
+	case "text":
+		min := viper.GetInt("bot.point.text.min")
+		max := viper.GetInt("bot.point.text.max")
+		switch {
+		case max <= min:
+		case random:
+			point = float64(randRange(min, max)) * scoreText(msg.Text, recent)
+		default:
+			point = float64(min+max) / 2 * scoreText(msg.Text, recent)
+		}
+	default:
+		point = viper.GetFloat64("bot.point." + kind.key)
+	}
+
+	// Media with a long enough caption earns a bonus
+	caption := len([]rune(strings.TrimSpace(msg.Caption)))
+	if caption > 0 && caption >= viper.GetInt("bot.point.caption.minLength") {
+		point += viper.GetFloat64("bot.point.caption.bonus")
+	}
+
+	if msg.ForwardOrigin != nil && viper.IsSet("bot.point.forwarded") {
+		point *= viper.GetFloat64("bot.point.forwarded")
 	}
 
 	return point
@@ -124,7 +184,7 @@ func formatRankingMessage(title string, points []database.Point) string {
 // It includes the user's name, username, points earned/lost, reasons, and timestamps.
 func formatHistory(chatUser *models.User, history []database.Point, limit int) string {
 	var msg strings.Builder
-	msg.WriteString(fmt.Sprintf("*Last %d point history for %s:*\n\n", limit, displayName(chatUser.FirstName, chatUser.Username)))
+	msg.WriteString(fmt.Sprintf("*Last %d point history for %s:*\n\n", limit, escapeMarkdown(displayName(chatUser.FirstName, chatUser.Username))))
 
 	// Iterate through history and append formatted records
 	for _, entry := range history {
@@ -135,7 +195,12 @@ func formatHistory(chatUser *models.User, history []database.Point, limit int) s
 			symbol = "➖" // Change symbol if points were lost
 		}
 
-		msg.WriteString(fmt.Sprintf("🕒 %s %s %.2f points (%s)\n", timeFormatted, symbol, entry.Amount, entry.Source))
+		source := entry.Source
+		if entry.Reason != "" {
+			source += ": " + escapeMarkdown(entry.Reason)
+		}
+
+		msg.WriteString(fmt.Sprintf("🕒 %s %s %.2f points (%s)\n", timeFormatted, symbol, entry.Amount, source))
 	}
 
 	return msg.String()
@@ -217,6 +282,101 @@ func formatDuration(hours int) string {
 	return fmt.Sprintf("%d hour(s)", hours) // Returns the duration in hours
 }
 
+// mention links to a user by ID, showing their name when it is known.
+func mention(userID int64, user *models.User) string {
+	name := strconv.FormatInt(userID, 10)
+	if user != nil && user.FirstName != "" {
+		name = user.FirstName
+	}
+	return fmt.Sprintf("[%s](tg://user?id=%d)", name, userID)
+}
+
+// markdownEscaper escapes the characters that start an entity in Markdown.
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// escapeMarkdown makes user supplied text safe to send with Markdown, so a
+// stray underscore or asterisk can't break the message.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// chatMember looks up a user in a chat. It returns nil if the lookup fails.
+func chatMember(ctx context.Context, b *bot.Bot, chatID, userID int64) *models.ChatMember {
+	member, err := b.GetChatMember(ctx, &bot.GetChatMemberParams{ChatID: chatID, UserID: userID})
+	if err != nil {
+		return nil
+	}
+	return member
+}
+
+// lookupUser returns the user with the given ID: the author of the replied-to
+// message if it is them, or else whoever the chat knows by that ID. It returns
+// nil if the user can't be found.
+func lookupUser(ctx context.Context, b *bot.Bot, msg *models.Message, userID int64) *models.User {
+	if reply := msg.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == userID {
+		return reply.From
+	}
+
+	member := chatMember(ctx, b, msg.Chat.ID, userID)
+	if member == nil {
+		return nil
+	}
+	return memberUser(*member)
+}
+
+// commandTarget returns the user a command is aimed at: the author of the
+// replied-to message, or else the user ID given as the first argument.
+// The remaining arguments are returned as well.
+func commandTarget(msg *models.Message, args []string) (int64, []string, bool) {
+	// Messages inside a forum topic reply to the service message that created it
+	if reply := msg.ReplyToMessage; reply != nil && reply.ForumTopicCreated == nil && reply.From != nil {
+		return reply.From.ID, args, true
+	}
+
+	if len(args) == 0 {
+		return 0, nil, false
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, nil, false
+	}
+	return userID, args[1:], true
+}
+
+// parseDuration parses a duration like "30m", "2h" or "2d". On top of the units
+// accepted by time.ParseDuration it understands "d" for days.
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// humanDuration formats d as days, hours and minutes, e.g. "1d 4h" or "30m".
+func humanDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
 // calculateLuckyBonus calculates a random bonus between 10% and 50% of the points
 func calculateLuckyBonus(points float64) float64 {
 	bonusPercentage := 10 + rand.IntN(41)
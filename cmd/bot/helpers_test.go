@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"snake_case", `snake\_case`},
+		{"*bold* and `code`", "\\*bold\\* and \\`code\\`"},
+		{"[link](url)", `\[link](url)`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeMarkdown(tt.in); got != tt.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatHistoryEscapesReasons(t *testing.T) {
+	user := &models.User{FirstName: "Ann"}
+	history := []database.Point{{Source: "seize", Reason: "spam_bot *links*", Amount: 5, Change: "loss"}}
+
+	got := formatHistory(user, history, 1)
+	if want := "(seize: spam\\_bot \\*links\\*)"; !strings.Contains(got, want) {
+		t.Errorf("formatHistory() = %q, want it to contain %q", got, want)
+	}
+}
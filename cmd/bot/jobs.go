@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/spf13/viper"
+)
+
+const (
+	// pruneInterval is how often old records are pruned.
+	pruneInterval = time.Hour
+
+	// eventInterval is how often the start and end of events are checked.
+	eventInterval = time.Minute
+)
+
+// prune periodically deletes records the bot no longer needs:
+//   - raw point history older than bot.history.retentionDays. Daily totals are
+//     kept, so rankings still work. A retention of 0 (the default) keeps the
+//     history forever.
+//   - message authors and rewarded reactions older than bot.reactions.maxAge
+//     hours, since those messages can no longer earn reaction points.
+//   - reply counts of past days, which no longer count towards bot.engagement.pairDailyCap.
+func (app *application) prune(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if days := viper.GetInt("bot.history.retentionDays"); days > 0 {
+			n, err := app.models.Points.Prune(ctx, days)
+			if err != nil {
+				log.Printf("Failed to prune point history: %v\n", err)
+			} else if n > 0 {
+				log.Printf("Pruned %d point history records older than %d days\n", n, days)
+			}
+		}
+
+		_, err := app.models.Messages.Prune(ctx, reactionMaxAge())
+		if err != nil {
+			log.Printf("Failed to prune messages: %v\n", err)
+		}
+
+		_, err = app.models.Engagements.Prune(ctx, time.Now())
+		if err != nil {
+			log.Printf("Failed to prune engagements: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runCaptchas kicks newcomers whose captcha expired every captchaInterval.
+func (app *application) runCaptchas(ctx context.Context, b *bot.Bot) {
+	ticker := time.NewTicker(captchaInterval)
+	defer ticker.Stop()
+
+	for {
+		app.expireCaptchas(ctx, b, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runEvents announces the start and end of events every eventInterval.
+func (app *application) runEvents(ctx context.Context, b *bot.Bot) {
+	ticker := time.NewTicker(eventInterval)
+	defer ticker.Stop()
+
+	for {
+		app.announceEvents(ctx, b, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+
+	"github.com/joybiswas007/modbot-tg/internal/database"
+)
+
+// credit adds amount to the user's balance, creating the user if needed, and
+// records it in the point history under source.
+func (app *application) credit(ctx context.Context, chatID, userID int64, amount float64, source string) error {
+	// Insert adds to the existing balance when the user is already known
+	err := app.models.Users.Insert(ctx, chatID, userID, amount)
+	if err != nil {
+		return err
+	}
+
+	return app.models.Points.Insert(ctx, &database.Point{
+		ChatID: chatID,
+		UserID: userID,
+		Amount: amount,
+		Source: source,
+		Change: "gain",
+	})
+}
+
+// debit removes up to amount from the user's balance without letting it go
+// negative, and records the removed points in the point history under source.
+// It returns how many points were actually removed.
+func (app *application) debit(ctx context.Context, chatID, userID int64, amount float64, source string) (float64, error) {
+	user, err := app.models.Users.Get(ctx, chatID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, nil
+	}
+
+	amount = min(amount, user.Points)
+	if amount <= 0 {
+		return 0, nil
+	}
+
+	err = app.models.Users.Update(ctx, chatID, userID, user.Points-amount)
+	if err != nil {
+		return 0, err
+	}
+
+	err = app.models.Points.Insert(ctx, &database.Point{
+		ChatID: chatID,
+		UserID: userID,
+		Amount: amount,
+		Source: source,
+		Change: "loss",
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return amount, nil
+}
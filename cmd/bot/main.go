@@ -9,46 +9,109 @@ import (
 	"os/signal"
 
 	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
 	"github.com/joybiswas007/modbot-tg/config"
 	"github.com/joybiswas007/modbot-tg/internal/database"
 	"github.com/spf13/viper"
 )
 
 type application struct {
-	models database.Models
+	models   database.Models
+	throttle *throttle
+	flood    *floodGuard
 }
 
 func main() {
 	var cfgFile string
 
 	flag.StringVar(&cfgFile, "config", "", "config file (default is .modbot.yaml)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: modbot [-config file] [migrate up [N] | down [N] | version | force N]\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	config.InitConfig(cfgFile)
 
-	token := viper.GetString("bot.token")
-	if token == "" {
-		log.Fatal("bot.token field is empty")
-	}
-
 	db := database.New()
 	defer func() {
 		db.Close()
 		log.Println("Disconnected from database")
 	}()
 
+	// "modbot migrate ..." manages the schema and exits without starting the bot
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(db, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	token := viper.GetString("bot.token")
+	if token == "" {
+		log.Fatal("bot.token field is empty")
+	}
+
+	if err := validatePointConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateSenderConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateEventConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateProbationConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateWarnConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateFloodConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateCaptchaConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateReportConfig(); err != nil {
+		log.Fatal(err)
+	}
+
 	if err := database.Migrate(db); err != nil {
 		log.Fatal(err)
 	}
 
 	app := &application{
-		models: database.NewModels(db),
+		models:   database.NewModels(db),
+		throttle: newThrottle(),
+		flood:    newFloodGuard(),
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	b, err := bot.New(token)
+	// Reactions, member updates and button presses are only delivered when requested explicitly.
+	// message_reaction_count is left out on purpose: it only carries the totals of
+	// anonymous reactions, which can't be capped per reactor or told apart from
+	// the author reacting to their own message.
+	b, err := bot.New(token,
+		bot.WithAllowedUpdates(bot.AllowedUpdates{
+			models.AllowedUpdateMessage,
+			models.AllowedUpdateEditedMessage,
+			models.AllowedUpdateMessageReaction,
+			models.AllowedUpdateChatMember,
+			models.AllowedUpdateCallbackQuery,
+		}),
+		// Flood and filter checks run before any handler, so commands and edits can't skip them
+		bot.WithMiddlewares(topicMiddleware, app.floodMiddleware, app.filterMiddleware),
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -57,15 +120,47 @@ func main() {
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/gift", bot.MatchTypePrefix, ensureGroupChat(app.gift))
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/buy", bot.MatchTypePrefix, ensureGroupChat(app.buyItem))
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/seize", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.seize)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/topicpoints", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.topicPoints)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/event", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.event)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/probation", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.probation)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/trust", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.trust)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/warns", bot.MatchTypePrefix, ensureGroupChat(app.warns))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/warn", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.warn)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/unwarn", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.unwarn)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/resetwarns", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.resetWarns)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/auditlog", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.auditlog)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/setlog", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.setLog)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/reports", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.reportSubscription)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/report", bot.MatchTypePrefix, ensureGroupChat(app.report))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/setwelcome", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.setWelcome)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/setgoodbye", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.setGoodbye)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/greetings", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.greetingsSettings)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/filter", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.filter)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/mute", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.mute)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/unmute", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.unmute)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/kick", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.kick)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/ban", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.ban)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/tban", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.tempBan)))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/unban", bot.MatchTypePrefix, ensureGroupChat(adminMiddleware(app.unban)))
 
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/stats", bot.MatchTypeExact, ensureGroupChat(app.userStats))
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/shop", bot.MatchTypeExact, ensureGroupChat(app.shop))
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/purge", bot.MatchTypeExact, ensureGroupChat(adminMiddleware(app.purge)))
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/history", bot.MatchTypeExact, ensureGroupChat(adminMiddleware(app.pointHistory)))
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/boost", bot.MatchTypeExact, ensureGroupChat(app.boost))
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, app.start)
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/id", bot.MatchTypeExact, app.getID)
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/help", bot.MatchTypeExact, app.help)
 
+	b.RegisterHandlerMatchFunc(isChatMigration, ensureGroupChat(app.migrateChat))
+	b.RegisterHandlerMatchFunc(isReaction, app.reaction)
+	b.RegisterHandlerMatchFunc(isEdit, app.editMessage)
+	b.RegisterHandlerMatchFunc(isMemberUpdate, app.memberUpdate)
+
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, unmuteButton, bot.MatchTypePrefix, adminCallbackMiddleware(app.unmuteFlood))
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, reportButton, bot.MatchTypePrefix, adminCallbackMiddleware(app.handleReport))
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, captchaButton, bot.MatchTypePrefix, app.captchaAnswer)
+
 	b.RegisterHandler(bot.HandlerTypeMessageText, "", bot.MatchTypePrefix, ensureGroupChat(app.countMessage))
 
 	me, err := b.GetMe(ctx)
@@ -75,5 +170,9 @@ func main() {
 
 	fmt.Printf("@%s started...\n", me.Username)
 
+	go app.prune(ctx)
+	go app.runEvents(ctx, b)
+	go app.runCaptchas(ctx, b)
+
 	b.Start(ctx)
 }
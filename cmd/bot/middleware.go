@@ -9,6 +9,11 @@ import (
 	"github.com/spf13/viper"
 )
 
+// isGroupChat reports whether chat is a group or supergroup.
+func isGroupChat(chat models.Chat) bool {
+	return chat.Type != models.ChatTypePrivate && chat.Type != models.ChatTypeChannel
+}
+
 // ensureGroupChat blocks execution if the chat is private or a channel.
 func ensureGroupChat(next bot.HandlerFunc) bot.HandlerFunc {
 	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
@@ -21,6 +26,33 @@ func ensureGroupChat(next bot.HandlerFunc) bot.HandlerFunc {
 	}
 }
 
+// adminCallbackMiddleware is the adminMiddleware for inline buttons: only admins
+// of the chat the button was posted in may press it. Buttons on messages too
+// old for Telegram to share are ignored.
+func adminCallbackMiddleware(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		query := update.CallbackQuery
+		if query.Message.Message == nil {
+			answerCallback(ctx, b, query, "This message is too old.")
+			return
+		}
+
+		admins, err := getAdmins(ctx, b, query.Message.Message.Chat.ID)
+		if err != nil {
+			log.Printf("Failed to retrieve admins: %v\n", err)
+			answerCallback(ctx, b, query, "Unable to verify admin status. Please try again later.")
+			return
+		}
+
+		if !isAdmin(query.From.ID, admins) {
+			answerCallback(ctx, b, query, "Only admins can use this button.")
+			return
+		}
+
+		next(ctx, b, update)
+	}
+}
+
 // adminMiddleware is a middleware that ensures the user issuing the command is an admin or the owner of the chat.
 // If the user is not an admin, they will receive an error message, and the command will not proceed.
 // It also handles the case where the command is issued by the "GroupAnonymousBot".
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+)
+
+const migrateUsage = "usage: modbot migrate up [N] | down [N] | version | force N"
+
+// runMigrate handles the "migrate" subcommand so operators can apply,
+// roll back, inspect or force the schema version explicitly.
+//
+// - up [N]: apply all pending migrations, or only the next N.
+// - down [N]: roll back the last N migrations (default 1).
+// - version: print the current schema version and dirty flag.
+// - force N: set the schema version to N and clear the dirty flag.
+func runMigrate(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	m, err := database.NewMigrate(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := migrateSteps(args[1:], 0)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			err = m.Steps(n)
+		} else {
+			err = m.Up()
+		}
+		if err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("migrate up failed: %v", err)
+		}
+	case "down":
+		n, err := migrateSteps(args[1:], 1)
+		if err != nil {
+			return err
+		}
+		err = m.Steps(-n)
+		if err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("migrate down failed: %v", err)
+		}
+	case "version":
+		// Printed below for every subcommand
+	case "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %v", args[1], err)
+		}
+		if err := m.Force(version); err != nil {
+			return fmt.Errorf("migrate force failed: %v", err)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		if err == migrate.ErrNilVersion {
+			log.Println("No migrations applied")
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("Database version: %d (dirty: %t)\n", version, dirty)
+	return nil
+}
+
+// migrateSteps parses the optional step count argument of "up" and "down".
+func migrateSteps(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	if len(args) > 1 {
+		return 0, errors.New(migrateUsage)
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid step count %q", args[0])
+	}
+	return n, nil
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+const (
+	// maxPurge is the most messages a single /purge can delete.
+	maxPurge = 1000
+
+	// deleteBatchSize is the most messages Telegram deletes in one request.
+	deleteBatchSize = 100
+
+	// Telegram treats mutes and bans shorter than minSanction or longer than
+	// maxSanction as permanent.
+	minSanction = 30 * time.Second
+	maxSanction = 366 * 24 * time.Hour
+)
+
+// Sanctions the bot can put on a member
+const (
+	sanctionMute = "mute" // Can't send messages, for a while or forever
+	sanctionKick = "kick" // Removed from the chat but free to rejoin
+	sanctionBan  = "ban"  // Removed from the chat and can't rejoin, for a while or forever
+)
+
+// purge deletes every message from the replied-to one up to the command itself.
+// With bot.purge.revokePoints enabled, the points those messages earned are taken back.
+func (app *application) purge(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.Message
+	chatID := msg.Chat.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	if msg.ReplyToMessage == nil {
+		message := "Usage: Reply to the first message to delete with `/purge`."
+		sendMessage(ctx, b, chatID, msg.ID, message, true, deleteCmd)
+		return
+	}
+
+	fromID, toID := msg.ReplyToMessage.ID, msg.ID
+	if toID-fromID+1 > maxPurge {
+		sendMessage(ctx, b, chatID, msg.ID, fmt.Sprintf(ErrPurgeTooLarge, maxPurge), true, deleteCmd)
+		return
+	}
+
+	admins, err := getAdmins(ctx, b, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	me, err := b.GetMe(ctx)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	// The bot itself needs the right to delete messages
+	if perm := checkBotPermission(me.ID, admins); perm == nil || !perm.CanDeleteMessages {
+		sendMessage(ctx, b, chatID, msg.ID, ErrBotCannotDelete, true, deleteCmd)
+		return
+	}
+
+	var revoked float64
+	if viper.GetBool("bot.purge.revokePoints") {
+		records, err := app.models.Messages.Range(ctx, chatID, fromID, toID)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msg.ID, ErrUnknownError, true, deleteCmd)
+			return
+		}
+
+		revoked = app.revokeMessagePoints(ctx, chatID, records, pointSources[10])
+	}
+
+	ids := make([]int, 0, toID-fromID+1)
+	for id := fromID; id <= toID; id++ {
+		ids = append(ids, id)
+	}
+
+	deleteMessages(ctx, b, chatID, ids)
+
+	log.Printf("User %d purged messages %d-%d in chat %d, revoking %.2f points\n", msg.From.ID, fromID, toID, chatID, revoked)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  "purge",
+		ActorID: msg.From.ID,
+		Actor:   msg.From,
+		Amount:  revoked,
+		Details: fmt.Sprintf("Messages %d-%d", fromID, toID),
+	})
+
+	report := fmt.Sprintf("🧹 Purged %d messages.", len(ids))
+	if revoked > 0 {
+		report += fmt.Sprintf(" %.2f points earned by them were revoked.", revoked)
+	}
+	sendMessage(ctx, b, chatID, msg.ID, report, false, false)
+}
+
+// deleteMessages deletes the given messages in batches of deleteBatchSize.
+// Telegram skips messages that are already gone or too old to delete.
+func deleteMessages(ctx context.Context, b *bot.Bot, chatID int64, ids []int) {
+	for start := 0; start < len(ids); start += deleteBatchSize {
+		end := min(start+deleteBatchSize, len(ids))
+		_, err := b.DeleteMessages(ctx, &bot.DeleteMessagesParams{
+			ChatID:     chatID,
+			MessageIDs: ids[start:end],
+		})
+		if err != nil {
+			log.Printf("Failed to delete messages %d-%d: %v\n", ids[start], ids[end-1], err)
+		}
+	}
+}
+
+// revokeMessagePoints takes back the points the given messages earned their
+// authors, recorded under source, and returns how many points were removed.
+func (app *application) revokeMessagePoints(ctx context.Context, chatID int64, records []database.Message, source string) float64 {
+	var revoked float64
+	for _, record := range records {
+		if record.Points <= 0 {
+			continue
+		}
+
+		removed, err := app.debit(ctx, chatID, record.UserID, record.Points, source)
+		if err != nil {
+			log.Printf("Failed to revoke points of message %d: %v\n", record.MessageID, err)
+			continue
+		}
+		revoked += removed
+
+		// Revoking the same message again must not take its points twice
+		err = app.models.Messages.SetPoints(ctx, chatID, record.MessageID, 0)
+		if err != nil {
+			log.Printf("Failed to record message points: %v\n", err)
+		}
+	}
+	return revoked
+}
+
+// checkSanctionDuration reports a timed mute or ban Telegram would turn into a
+// permanent one. A duration of 0 is meant to be permanent and passes.
+func checkSanctionDuration(d time.Duration) error {
+	if d != 0 && (d < minSanction || d > maxSanction) {
+		return fmt.Errorf("duration %s must be between 30s and 366d", d)
+	}
+	return nil
+}
+
+// applySanction mutes, kicks or bans a member. Mutes and bans last for d, or
+// forever when d is 0.
+func applySanction(ctx context.Context, b *bot.Bot, chatID, userID int64, sanction string, d time.Duration) error {
+	var until time.Time
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+
+	switch sanction {
+	case sanctionMute:
+		return muteMember(ctx, b, chatID, userID, until)
+	case sanctionKick:
+		return kickMember(ctx, b, chatID, userID)
+	case sanctionBan:
+		return banMember(ctx, b, chatID, userID, until)
+	default:
+		return fmt.Errorf("unknown sanction %q", sanction)
+	}
+}
+
+// formatSanction describes a sanction that was applied, e.g. "🔇 Muted for 1h".
+func formatSanction(sanction string, d time.Duration) string {
+	var text string
+	switch sanction {
+	case sanctionMute:
+		text = "🔇 Muted"
+	case sanctionKick:
+		return "👢 Kicked"
+	case sanctionBan:
+		text = "🔨 Banned"
+	default:
+		return sanction
+	}
+
+	if d > 0 {
+		return text + " for " + humanDuration(d)
+	}
+	return text
+}
+
+// muteMember stops a member from sending anything until the given time, or
+// forever if until is zero.
+func muteMember(ctx context.Context, b *bot.Bot, chatID, userID int64, until time.Time) error {
+	_, err := b.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+		ChatID:      chatID,
+		UserID:      userID,
+		Permissions: &models.ChatPermissions{},
+		UntilDate:   untilDate(until),
+	})
+	return err
+}
+
+// unmuteMember gives a muted member the default permissions of the chat back.
+func unmuteMember(ctx context.Context, b *bot.Bot, chatID, userID int64) error {
+	chat, err := b.GetChat(ctx, &bot.GetChatParams{ChatID: chatID})
+	if err != nil {
+		return err
+	}
+
+	permissions := chat.Permissions
+	if permissions == nil {
+		permissions = &models.ChatPermissions{
+			CanSendMessages:       true,
+			CanSendAudios:         true,
+			CanSendDocuments:      true,
+			CanSendPhotos:         true,
+			CanSendVideos:         true,
+			CanSendVideoNotes:     true,
+			CanSendVoiceNotes:     true,
+			CanSendPolls:          true,
+			CanSendOtherMessages:  true,
+			CanAddWebPagePreviews: true,
+		}
+	}
+
+	_, err = b.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+		ChatID:      chatID,
+		UserID:      userID,
+		Permissions: permissions,
+	})
+	return err
+}
+
+// banMember removes a member from the chat until the given time, or forever if
+// until is zero.
+func banMember(ctx context.Context, b *bot.Bot, chatID, userID int64, until time.Time) error {
+	_, err := b.BanChatMember(ctx, &bot.BanChatMemberParams{
+		ChatID:    chatID,
+		UserID:    userID,
+		UntilDate: untilDate(until),
+	})
+	return err
+}
+
+// unbanMember lets a banned user join the chat again.
+func unbanMember(ctx context.Context, b *bot.Bot, chatID, userID int64) error {
+	_, err := b.UnbanChatMember(ctx, &bot.UnbanChatMemberParams{
+		ChatID:       chatID,
+		UserID:       userID,
+		OnlyIfBanned: true,
+	})
+	return err
+}
+
+// kickMember removes a member from the chat without banning them.
+func kickMember(ctx context.Context, b *bot.Bot, chatID, userID int64) error {
+	if err := banMember(ctx, b, chatID, userID, time.Time{}); err != nil {
+		return err
+	}
+	return unbanMember(ctx, b, chatID, userID)
+}
+
+// untilDate converts until to the Unix time Telegram expects, 0 meaning forever.
+func untilDate(until time.Time) int {
+	if until.IsZero() {
+		return 0
+	}
+	return int(until.Unix())
+}
+
+// botRights returns the admin rights of the bot in a chat, or nil if it is not an admin.
+func botRights(ctx context.Context, b *bot.Bot, chatID int64) (*models.ChatMemberAdministrator, error) {
+	admins, err := getAdmins(ctx, b, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	me, err := b.GetMe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkBotPermission(me.ID, admins), nil
+}
+
+// mute stops a member from sending messages, for a while or forever.
+// (e.g.): "/mute userid [duration] [reason]" || reply with "/mute [duration] [reason]"
+func (app *application) mute(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.sanction(ctx, b, update, "/mute", sanctionMute, false)
+}
+
+// kick removes a member from the chat without banning them.
+// (e.g.): "/kick userid [reason]" || reply with "/kick [reason]"
+func (app *application) kick(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.sanction(ctx, b, update, "/kick", sanctionKick, false)
+}
+
+// ban removes a member from the chat for good.
+// (e.g.): "/ban userid [reason]" || reply with "/ban [reason]"
+func (app *application) ban(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.sanction(ctx, b, update, "/ban", sanctionBan, false)
+}
+
+// tempBan removes a member from the chat for a while.
+// (e.g.): "/tban userid 2d [reason]" || reply with "/tban 2d [reason]"
+func (app *application) tempBan(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.sanction(ctx, b, update, "/tban", sanctionBan, true)
+}
+
+// sanction applies a sanction to the member a command is aimed at. The
+// duration is read from the first argument after the user, and must be given
+// when timed is true. Mutes take an optional duration, kicks and permanent bans none.
+func (app *application) sanction(ctx context.Context, b *bot.Bot, update *models.Update, command, sanction string, timed bool) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+	adminID := update.Message.From.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	usage := fmt.Sprintf("Usage: Reply to a users message with `%s [reason]` or use `%s user_id [reason]`.", command, command)
+	switch {
+	case timed:
+		usage = fmt.Sprintf("Usage: Reply to a users message with `%s duration [reason]` or use `%s user_id duration [reason]`, e.g. `%s 2d`.", command, command, command)
+	case sanction == sanctionMute:
+		usage = fmt.Sprintf("Usage: Reply to a users message with `%s [duration] [reason]` or use `%s user_id [duration] [reason]`.", command, command)
+	}
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, command, "", 1)))
+	userID, rest, ok := commandTarget(update.Message, args)
+	if !ok {
+		sendMessage(ctx, b, chatID, msgId, usage, true, deleteCmd)
+		return
+	}
+
+	var duration time.Duration
+	if timed || sanction == sanctionMute {
+		if len(rest) > 0 {
+			if d, err := parseDuration(rest[0]); err == nil && d > 0 {
+				duration, rest = d, rest[1:]
+			}
+		}
+		if checkSanctionDuration(duration) != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidSanctionDuration+"\n"+usage, true, deleteCmd)
+			return
+		}
+		if timed && duration == 0 {
+			sendMessage(ctx, b, chatID, msgId, usage, true, deleteCmd)
+			return
+		}
+	}
+	reason := strings.Join(rest, " ")
+
+	if userID == adminID {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotSanctionSelf, true, deleteCmd)
+		return
+	}
+
+	admins, err := getAdmins(ctx, b, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	// Admins can't be restricted, and acting on them is left to the owner
+	if isAdmin(userID, admins) {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotSanctionAdmin, true, deleteCmd)
+		return
+	}
+
+	me, err := b.GetMe(ctx)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	// The bot itself needs the right to restrict and ban members
+	if perm := checkBotPermission(me.ID, admins); perm == nil || !perm.CanRestrictMembers {
+		sendMessage(ctx, b, chatID, msgId, ErrBotCannotRestrict, true, deleteCmd)
+		return
+	}
+
+	if userID == me.ID {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotSanctionSelf, true, deleteCmd)
+		return
+	}
+
+	err = applySanction(ctx, b, chatID, userID, sanction, duration)
+	if err != nil {
+		log.Printf("Failed to %s user %d in chat %d: %v\n", sanction, userID, chatID, err)
+		sendMessage(ctx, b, chatID, msgId, ErrSanctionFailed, true, deleteCmd)
+		return
+	}
+
+	log.Printf("User %d in chat %d by %d: %s (reason: %q)\n", userID, chatID, adminID, formatSanction(sanction, duration), reason)
+
+	user := lookupUser(ctx, b, update.Message, userID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   sanction,
+		ActorID:  adminID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+		Target:   user,
+		Duration: duration,
+		Reason:   reason,
+	})
+
+	msg := fmt.Sprintf("%s: %s", formatSanction(sanction, duration), mention(userID, user))
+	if reason != "" {
+		msg += fmt.Sprintf("\n📝 Reason: %s", escapeMarkdown(reason))
+	}
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
+
+// unmute lets a muted member send messages again.
+// (e.g.): "/unmute userid" || reply with "/unmute"
+func (app *application) unmute(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.lift(ctx, b, update, "/unmute", unmuteMember, "🔊 Unmuted")
+}
+
+// unban lets a banned user join the chat again.
+// (e.g.): "/unban userid" || reply with "/unban"
+func (app *application) unban(ctx context.Context, b *bot.Bot, update *models.Update) {
+	app.lift(ctx, b, update, "/unban", unbanMember, "🕊 Unbanned")
+}
+
+// lift undoes a sanction on the user a command is aimed at.
+func (app *application) lift(ctx context.Context, b *bot.Bot, update *models.Update, command string,
+	undo func(ctx context.Context, b *bot.Bot, chatID, userID int64) error, done string) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, command, "", 1)))
+	userID, _, ok := commandTarget(update.Message, args)
+	if !ok {
+		msg := fmt.Sprintf("Usage: Reply to a users message with `%s` or use `%s user_id`.", command, command)
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	perm, err := botRights(ctx, b, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	if perm == nil || !perm.CanRestrictMembers {
+		sendMessage(ctx, b, chatID, msgId, ErrBotCannotRestrict, true, deleteCmd)
+		return
+	}
+
+	err = undo(ctx, b, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to %s user %d in chat %d: %v\n", strings.TrimPrefix(command, "/"), userID, chatID, err)
+		sendMessage(ctx, b, chatID, msgId, ErrSanctionFailed, true, deleteCmd)
+		return
+	}
+
+	log.Printf("User %d in chat %d by %d: %s\n", userID, chatID, update.Message.From.ID, done)
+
+	user := lookupUser(ctx, b, update.Message, userID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   strings.TrimPrefix(command, "/"),
+		ActorID:  update.Message.From.ID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+		Target:   user,
+	})
+
+	msg := fmt.Sprintf("%s: %s", done, mention(userID, user))
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckSanctionDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		ok       bool
+	}{
+		{"permanent", 0, true},
+		{"too short", 10 * time.Second, false},
+		{"shortest", 30 * time.Second, true},
+		{"longest", 366 * 24 * time.Hour, true},
+		{"too long", 400 * 24 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSanctionDuration(tt.duration); (err == nil) != tt.ok {
+				t.Errorf("checkSanctionDuration(%s) = %v, want ok %v", tt.duration, err, tt.ok)
+			}
+		})
+	}
+}
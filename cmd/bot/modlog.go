@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+const (
+	// auditDefaultLimit is how many entries /auditlog shows without a count.
+	auditDefaultLimit = 20
+
+	// auditMaxLimit is the most entries /auditlog shows, longer lists don't fit
+	// in one message and have to be exported.
+	auditMaxLimit = 50
+)
+
+// adminAction describes something an admin, or the bot on its own, did to a member.
+type adminAction struct {
+	Action   string        // What was done, e.g. "seize", "warn" or "mute"
+	ActorID  int64         // The admin, 0 when the bot acted on its own
+	Actor    *models.User  // The admin, if known
+	TargetID int64         // The member it was done to
+	Target   *models.User  // The member, if known
+	Amount   float64       // Points taken or given, if any
+	Duration time.Duration // How long a mute or ban lasts, 0 for forever or not timed
+	Reason   string        // Why it was done, if given
+	Details  string        // Anything else worth knowing, e.g. the purged messages
+}
+
+// auditParams are the parameters of an admin action, stored as JSON in the audit log.
+type auditParams struct {
+	Amount   float64 `json:"amount,omitempty"`
+	Duration string  `json:"duration,omitempty"` // e.g. "2h0m0s"
+	Details  string  `json:"details,omitempty"`
+}
+
+// logAction records an admin action in the audit log and posts it to the log
+// channel of the chat, if one was set with /setlog.
+func (app *application) logAction(ctx context.Context, b *bot.Bot, chatID int64, action adminAction) {
+	params := auditParams{Amount: action.Amount, Details: action.Details}
+	if action.Duration > 0 {
+		params.Duration = action.Duration.String()
+	}
+
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		log.Printf("Failed to encode audit params: %v\n", err)
+		encoded = []byte("{}")
+	}
+
+	err = app.models.Audit.Insert(ctx, &database.AuditEntry{
+		ChatID:   chatID,
+		ActorID:  action.ActorID,
+		TargetID: action.TargetID,
+		Action:   action.Action,
+		Params:   string(encoded),
+		Reason:   action.Reason,
+	})
+	if err != nil {
+		log.Printf("Failed to store audit entry: %v\n", err)
+	}
+
+	settings, err := app.models.Settings.Get(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to fetch log channel: %v\n", err)
+		return
+	}
+	if settings == nil || settings.LogChatID == 0 {
+		return
+	}
+
+	title := strconv.FormatInt(chatID, 10)
+	if chat, err := b.GetChat(ctx, &bot.GetChatParams{ChatID: chatID}); err == nil {
+		title = chat.Title
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    settings.LogChatID,
+		Text:      formatAction(title, chatID, action),
+		ParseMode: models.ParseModeMarkdownV1,
+	})
+	if err != nil {
+		log.Printf("Failed to post to log channel %d of chat %d: %v\n", settings.LogChatID, chatID, err)
+	}
+}
+
+// formatAction builds the log channel record of an admin action. It starts
+// with a hashtag of the action so records are easy to search.
+func formatAction(title string, chatID int64, action adminAction) string {
+	var msg strings.Builder
+	// Hashtags end at a space, so "filter add" is tagged #FILTER_ADD
+	tag := strings.ReplaceAll(strings.ToUpper(action.Action), " ", "_")
+	msg.WriteString(fmt.Sprintf("#%s\n", escapeMarkdown(tag)))
+	msg.WriteString(fmt.Sprintf("💬 Chat: %s [`%d`]\n", escapeMarkdown(title), chatID))
+
+	if action.ActorID == 0 {
+		msg.WriteString("👮 By: 🤖 automatic\n")
+	} else {
+		msg.WriteString(fmt.Sprintf("👮 By: %s [`%d`]\n", mention(action.ActorID, action.Actor), action.ActorID))
+	}
+
+	if action.TargetID != 0 {
+		msg.WriteString(fmt.Sprintf("👤 User: %s [`%d`]\n", mention(action.TargetID, action.Target), action.TargetID))
+	}
+	if action.Duration > 0 {
+		msg.WriteString(fmt.Sprintf("⏱ Duration: %s\n", humanDuration(action.Duration)))
+	}
+	if action.Amount > 0 {
+		msg.WriteString(fmt.Sprintf("💰 Points: %.2f\n", action.Amount))
+	}
+	if action.Reason != "" {
+		msg.WriteString(fmt.Sprintf("📝 Reason: %s\n", escapeMarkdown(action.Reason)))
+	}
+	if action.Details != "" {
+		msg.WriteString(fmt.Sprintf("ℹ️ %s\n", escapeMarkdown(action.Details)))
+	}
+
+	return msg.String()
+}
+
+// setLog binds the channel admin actions of the chat are posted to. Both the
+// admin and the bot have to be admins of the channel, and the bot needs the
+// permission to post there.
+// (e.g.): "/setlog -1001234567890" || "/setlog off"
+func (app *application) setLog(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	arg := strings.TrimSpace(strings.Replace(update.Message.Text, "/setlog", "", 1))
+	if arg == "" {
+		msg := "Usage: `/setlog channel_id` or `/setlog off`. Add me to the channel as an admin who can post messages first."
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	var logChatID int64
+	if arg != "off" {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidUserID, true, deleteCmd)
+			return
+		}
+
+		channelAdmins, err := getAdmins(ctx, b, id)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrCannotPostToLog, true, deleteCmd)
+			return
+		}
+
+		// Only admins of the channel may send records there
+		if !isAdmin(update.Message.From.ID, channelAdmins) {
+			sendMessage(ctx, b, chatID, msgId, ErrNotLogAdmin, true, deleteCmd)
+			return
+		}
+
+		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    id,
+			Text:      fmt.Sprintf("📋 This channel now logs the admin actions of *%s*.", escapeMarkdown(update.Message.Chat.Title)),
+			ParseMode: models.ParseModeMarkdownV1,
+		})
+		if err != nil {
+			log.Printf("Failed to post to log channel %d: %v\n", id, err)
+			sendMessage(ctx, b, chatID, msgId, ErrCannotPostToLog, true, deleteCmd)
+			return
+		}
+		logChatID = id
+	}
+
+	err := app.models.Settings.SetLogChat(ctx, chatID, logChatID)
+	if err != nil {
+		log.Printf("Failed to set log channel: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("Log channel of chat %d set to %d by %d\n", chatID, logChatID, update.Message.From.ID)
+
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  "setlog",
+		ActorID: update.Message.From.ID,
+		Actor:   update.Message.From,
+		Details: fmt.Sprintf("Log channel: %d", logChatID),
+	})
+
+	msg := "📋 Admin actions are no longer logged."
+	if logChatID != 0 {
+		msg = "📋 Admin actions are now logged to the channel."
+	}
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
+
+// auditlog shows the latest admin actions of the chat, optionally only those
+// about one member. With "export" it sends them all as a CSV file instead.
+// (e.g.): "/auditlog" || "/auditlog userid 50" || "/auditlog all 50" || "/auditlog export"
+func (app *application) auditlog(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	usage := "Usage: `/auditlog [user_id|all] [n]` or `/auditlog export [user_id]`, or reply to a users message with `/auditlog [n]`."
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/auditlog", "", 1)))
+
+	export := len(args) > 0 && args[0] == "export"
+	if export {
+		args = args[1:]
+	}
+
+	var userID int64
+	if len(args) > 0 && args[0] == "all" {
+		args = args[1:]
+	} else if id, rest, ok := commandTarget(update.Message, args); ok {
+		userID, args = id, rest
+	}
+
+	limit := auditDefaultLimit
+	switch {
+	case export && len(args) == 0:
+		limit = 0
+	case !export && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			sendMessage(ctx, b, chatID, msgId, usage, true, deleteCmd)
+			return
+		}
+		limit = min(n, auditMaxLimit)
+	case len(args) > 0:
+		sendMessage(ctx, b, chatID, msgId, usage, true, deleteCmd)
+		return
+	}
+
+	entries, err := app.models.Audit.List(ctx, chatID, userID, limit)
+	if err != nil {
+		log.Printf("Failed to fetch audit log: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+	if len(entries) == 0 {
+		sendMessage(ctx, b, chatID, msgId, ErrNoAuditEntries, true, deleteCmd)
+		return
+	}
+
+	if export {
+		app.exportAudit(ctx, b, update.Message, entries, deleteCmd)
+		return
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("📋 *Last %d admin actions*\n\n", len(entries)))
+	for _, entry := range entries {
+		msg.WriteString(formatAuditEntry(entry))
+	}
+	sendMessage(ctx, b, chatID, msgId, msg.String(), true, deleteCmd)
+}
+
+// formatAuditEntry describes an audit entry on a single line.
+func formatAuditEntry(entry database.AuditEntry) string {
+	actor := "🤖"
+	if entry.ActorID != 0 {
+		actor = fmt.Sprintf("`%d`", entry.ActorID)
+	}
+
+	line := fmt.Sprintf("🕒 %s *%s* by %s", entry.CreatedAt.Format(time.DateTime), escapeMarkdown(entry.Action), actor)
+	if entry.TargetID != 0 {
+		line += fmt.Sprintf(" on `%d`", entry.TargetID)
+	}
+
+	var params auditParams
+	if err := json.Unmarshal([]byte(entry.Params), &params); err == nil {
+		if params.Amount > 0 {
+			line += fmt.Sprintf(", %.2f points", params.Amount)
+		}
+		if d, err := time.ParseDuration(params.Duration); err == nil {
+			line += ", " + humanDuration(d)
+		}
+	}
+
+	if entry.Reason != "" {
+		line += fmt.Sprintf(" (%s)", escapeMarkdown(entry.Reason))
+	}
+
+	return line + "\n"
+}
+
+// exportAudit sends audit entries as a CSV file in reply to msg.
+func (app *application) exportAudit(ctx context.Context, b *bot.Bot, msg *models.Message, entries []database.AuditEntry, deleteCmd bool) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "created_at", "actor_id", "target_id", "action", "params", "reason"})
+	for _, entry := range entries {
+		w.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.CreatedAt.Format(time.DateTime),
+			strconv.FormatInt(entry.ActorID, 10),
+			strconv.FormatInt(entry.TargetID, 10),
+			entry.Action,
+			entry.Params,
+			entry.Reason,
+		})
+	}
+	w.Flush()
+
+	params := &bot.SendDocumentParams{
+		ChatID:          msg.Chat.ID,
+		MessageThreadID: threadID(msg),
+		Document:        &models.InputFileUpload{Filename: fmt.Sprintf("audit-%d.csv", msg.Chat.ID), Data: &buf},
+		Caption:         fmt.Sprintf("📋 %d admin actions", len(entries)),
+	}
+	// The reply is dropped along with the command
+	if !deleteCmd {
+		params.ReplyParameters = &models.ReplyParameters{MessageID: msg.ID}
+	}
+
+	_, err := b.SendDocument(ctx, params)
+	if err != nil {
+		log.Printf("Failed to send audit export: %v\n", err)
+		sendMessage(ctx, b, msg.Chat.ID, msg.ID, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	if deleteCmd {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: msg.Chat.ID, MessageID: msg.ID})
+	}
+}
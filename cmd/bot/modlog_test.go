@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAction(t *testing.T) {
+	got := formatAction("my_chat", -100, adminAction{
+		Action:  "filter add",
+		ActorID: 1,
+		Reason:  "spam *links*",
+		Details: "Filter 3: buy_now",
+	})
+
+	for _, want := range []string{
+		"#FILTER\\_ADD\n",
+		"💬 Chat: my\\_chat [`-100`]\n",
+		"📝 Reason: spam \\*links\\*\n",
+		"ℹ️ Filter 3: buy\\_now\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatAction() = %q, want it to contain %q", got, want)
+		}
+	}
+}
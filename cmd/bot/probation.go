@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+// probationUsage explains the /probation arguments.
+const probationUsage = "Usage: `/probation duration messages rate`, e.g. `/probation 24h 20 0.5`, or `/probation off`. The duration is counted in whole hours like `12h` or `2d`, `0h` ignores it."
+
+// isMemberUpdate reports whether the update is a change of a member's status.
+// Telegram only sends these to bots that are admins in the group.
+func isMemberUpdate(update *models.Update) bool {
+	return update.ChatMember != nil
+}
+
+// memberUpdate records when members join, so newcomers can be put on probation.
+func (app *application) memberUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
+	change := update.ChatMember
+	user := memberUser(change.NewChatMember)
+	if user == nil || user.IsBot {
+		return
+	}
+
+	if isMember(change.OldChatMember) || !isMember(change.NewChatMember) {
+		return
+	}
+
+	err := app.models.Members.Join(ctx, change.Chat.ID, user.ID, time.Unix(int64(change.Date), 0))
+	if err != nil {
+		log.Printf("Failed to record member join: %v\n", err)
+	}
+}
+
+// isMember reports whether the status means the user is in the chat.
+func isMember(member models.ChatMember) bool {
+	switch member.Type {
+	case models.ChatMemberTypeOwner, models.ChatMemberTypeAdministrator, models.ChatMemberTypeMember:
+		return true
+	case models.ChatMemberTypeRestricted:
+		return member.Restricted.IsMember
+	default:
+		return false
+	}
+}
+
+// memberUser returns the user a chat member status belongs to.
+func memberUser(member models.ChatMember) *models.User {
+	switch member.Type {
+	case models.ChatMemberTypeOwner:
+		return member.Owner.User
+	case models.ChatMemberTypeAdministrator:
+		return &member.Administrator.User
+	case models.ChatMemberTypeMember:
+		return member.Member.User
+	case models.ChatMemberTypeRestricted:
+		return member.Restricted.User
+	case models.ChatMemberTypeLeft:
+		return member.Left.User
+	case models.ChatMemberTypeBanned:
+		return member.Banned.User
+	}
+	return nil
+}
+
+// probationPolicy returns the newcomer probation of a chat. Chats that never
+// set one with /probation use bot.probation from the config file.
+func (app *application) probationPolicy(ctx context.Context, chatID int64) (database.Probation, error) {
+	settings, err := app.models.Settings.Get(ctx, chatID)
+	if err != nil {
+		return database.Probation{}, err
+	}
+	if settings != nil && settings.Probation != nil {
+		return *settings.Probation, nil
+	}
+
+	return database.Probation{
+		Hours:    viper.GetInt("bot.probation.hours"),
+		Messages: viper.GetInt("bot.probation.messages"),
+		Rate:     viper.GetFloat64("bot.probation.rate"),
+	}, nil
+}
+
+// onProbation reports whether a member is still on probation at now. The
+// probation lasts until the member has been in the chat for the configured
+// hours and has sent the configured number of messages. Members the bot never
+// saw join and trusted members are never on probation.
+func onProbation(policy database.Probation, member *database.Member, now time.Time) bool {
+	if member == nil || member.Trusted || member.JoinedAt.IsZero() {
+		return false
+	}
+
+	newcomer := policy.Hours > 0 && now.Sub(member.JoinedAt) < time.Duration(policy.Hours)*time.Hour
+	quiet := policy.Messages > 0 && member.Messages < policy.Messages
+
+	return newcomer || quiet
+}
+
+// probationRate returns the fraction of the usual points the user earns at now:
+// the probation rate while they are on probation, 1 otherwise.
+func (app *application) probationRate(ctx context.Context, chatID, userID int64, now time.Time) (float64, error) {
+	member, err := app.models.Members.Get(ctx, chatID, userID)
+	if err != nil || member == nil {
+		return 1, err
+	}
+
+	policy, err := app.probationPolicy(ctx, chatID)
+	if err != nil {
+		return 1, err
+	}
+
+	if !onProbation(policy, member, now) {
+		return 1, nil
+	}
+	return policy.Rate, nil
+}
+
+// validateProbationConfig reports a bot.probation.rate outside of 0 to 1.
+func validateProbationConfig() error {
+	if rate := viper.GetFloat64("bot.probation.rate"); rate < 0 || rate > 1 {
+		return fmt.Errorf("invalid bot.probation.rate %v, it must be between 0 and 1", rate)
+	}
+	return nil
+}
+
+// probation shows or sets the newcomer probation of the chat
+// (e.g.): "/probation 24h 20 0.5" || "/probation off"
+func (app *application) probation(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	parts := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/probation", "", 1)))
+
+	var policy database.Probation
+	switch len(parts) {
+	case 0:
+		current, err := app.probationPolicy(ctx, chatID)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+		sendMessage(ctx, b, chatID, msgId, formatProbation(current), true, deleteCmd)
+		return
+	case 1:
+		if parts[0] != "off" {
+			sendMessage(ctx, b, chatID, msgId, probationUsage, true, deleteCmd)
+			return
+		}
+	case 3:
+		// Probations are stored in hours, so "30m" would silently become no time at all
+		duration, err := parseDuration(parts[0])
+		if err != nil || duration < 0 || duration%time.Hour != 0 {
+			sendMessage(ctx, b, chatID, msgId, probationUsage, true, deleteCmd)
+			return
+		}
+
+		messages, err := strconv.Atoi(parts[1])
+		if err != nil || messages < 0 {
+			sendMessage(ctx, b, chatID, msgId, probationUsage, true, deleteCmd)
+			return
+		}
+
+		rate, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidProbationRate, true, deleteCmd)
+			return
+		}
+
+		policy = database.Probation{Hours: int(duration.Hours()), Messages: messages, Rate: rate}
+	default:
+		sendMessage(ctx, b, chatID, msgId, probationUsage, true, deleteCmd)
+		return
+	}
+
+	err := app.models.Settings.SetProbation(ctx, chatID, policy)
+	if err != nil {
+		log.Printf("Failed to set probation: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("Probation in chat %d set by %d: %d hours, %d messages, rate %v\n",
+		chatID, update.Message.From.ID, policy.Hours, policy.Messages, policy.Rate)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  "probation",
+		ActorID: update.Message.From.ID,
+		Actor:   update.Message.From,
+		Details: formatProbation(policy),
+	})
+	sendMessage(ctx, b, chatID, msgId, formatProbation(policy), true, deleteCmd)
+}
+
+// formatProbation describes a probation policy.
+func formatProbation(policy database.Probation) string {
+	if policy.Hours <= 0 && policy.Messages <= 0 {
+		return "🆕 Newcomers earn points right away."
+	}
+
+	var terms []string
+	if policy.Hours > 0 {
+		terms = append(terms, fmt.Sprintf("their first %s", humanDuration(time.Duration(policy.Hours)*time.Hour)))
+	}
+	if policy.Messages > 0 {
+		terms = append(terms, fmt.Sprintf("their first %d messages", policy.Messages))
+	}
+
+	return fmt.Sprintf("🆕 Newcomers earn *%.0f%%* of the usual points during %s.", policy.Rate*100, strings.Join(terms, " and "))
+}
+
+// trust lets a member skip the newcomer probation.
+// (e.g.): "/trust userid" || reply with "/trust"
+func (app *application) trust(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/trust", "", 1)))
+	userID, _, ok := commandTarget(update.Message, args)
+	if !ok {
+		msg := "Usage: Reply to a users message with `/trust` or use `/trust user_id`."
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	err := app.models.Members.Trust(ctx, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to trust member: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("User %d trusted in chat %d by %d\n", userID, chatID, update.Message.From.ID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "trust",
+		ActorID:  update.Message.From.ID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+		Target:   lookupUser(ctx, b, update.Message, userID),
+	})
+	sendMessage(ctx, b, chatID, msgId, "✅ The user skips the newcomer probation and earns points right away.", true, deleteCmd)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+// defaultReactionMaxAge is how many hours a message keeps earning reaction
+// points when bot.reactions.maxAge is not set.
+const defaultReactionMaxAge = 48
+
+// isReaction reports whether the update is a change of a user's reactions to a message.
+func isReaction(update *models.Update) bool {
+	return update.MessageReaction != nil
+}
+
+// reactionMaxAge returns how many hours after it was sent a message keeps earning reaction points.
+func reactionMaxAge() int {
+	if hours := viper.GetInt("bot.reactions.maxAge"); hours > 0 {
+		return hours
+	}
+	return defaultReactionMaxAge
+}
+
+// reaction awards the author of a message points for every reaction added to
+// it and revokes them when the reaction is removed.
+//
+// Configured under bot.reactions:
+// - emoji: points per emoji, e.g. "🔥": 2.
+// - default: points for any other reaction, including custom emoji and paid reactions.
+// - messageCap: max points a single message can earn from reactions.
+// - reactorDailyCap: max rewarded reactions a member can give per day.
+// - maxAge: hours after which a message no longer earns reaction points.
+func (app *application) reaction(ctx context.Context, b *bot.Bot, update *models.Update) {
+	r := update.MessageReaction
+
+	// Anonymous reactions can't be capped per reactor, and bots don't count
+	if r.User == nil || r.User.IsBot {
+		return
+	}
+	if r.Chat.Type == models.ChatTypePrivate || r.Chat.Type == models.ChatTypeChannel {
+		return
+	}
+
+	msg, err := app.models.Messages.Get(ctx, r.Chat.ID, r.MessageID)
+	if err != nil {
+		log.Printf("Failed to fetch message %d: %v\n", r.MessageID, err)
+		return
+	}
+
+	// Unknown (or already pruned) messages and reactions to your own messages earn nothing
+	if msg == nil || msg.UserID == r.User.ID {
+		return
+	}
+
+	oldReactions := reactionKeys(r.OldReaction)
+	newReactions := reactionKeys(r.NewReaction)
+
+	for key := range oldReactions {
+		if !newReactions[key] {
+			app.revokeReaction(ctx, msg, r.User.ID, key)
+		}
+	}
+
+	if time.Since(msg.CreatedAt) > time.Duration(reactionMaxAge())*time.Hour {
+		return
+	}
+
+	for key := range newReactions {
+		if !oldReactions[key] {
+			app.awardReaction(ctx, msg, r.User.ID, key)
+		}
+	}
+}
+
+// awardReaction credits the message author for a single added reaction,
+// within the per-message and per-reactor caps.
+func (app *application) awardReaction(ctx context.Context, msg *database.Message, reactorID int64, key string) {
+	points := reactionPoints(key)
+	if points <= 0 {
+		return
+	}
+
+	if messageCap := viper.GetFloat64("bot.reactions.messageCap"); messageCap > 0 {
+		total, err := app.models.Reactions.MessageTotal(ctx, msg.ChatID, msg.MessageID)
+		if err != nil {
+			log.Printf("Failed to check reaction cap: %v\n", err)
+			return
+		}
+		points = min(points, messageCap-total)
+		if points <= 0 {
+			return
+		}
+	}
+
+	if reactorCap := viper.GetInt("bot.reactions.reactorDailyCap"); reactorCap > 0 {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		count, err := app.models.Reactions.CountByReactor(ctx, msg.ChatID, reactorID, today)
+		if err != nil {
+			log.Printf("Failed to check reaction cap: %v\n", err)
+			return
+		}
+		if count >= reactorCap {
+			return
+		}
+	}
+
+	stored, err := app.models.Reactions.Insert(ctx, &database.Reaction{
+		ChatID:    msg.ChatID,
+		MessageID: msg.MessageID,
+		ReactorID: reactorID,
+		Reaction:  key,
+		AuthorID:  msg.UserID,
+		Points:    points,
+	})
+	if err != nil {
+		log.Printf("Failed to record reaction: %v\n", err)
+		return
+	}
+	// Adding a reaction again after removing it earns nothing
+	if !stored {
+		return
+	}
+
+	err = app.credit(ctx, msg.ChatID, msg.UserID, points, pointSources[8])
+	if err != nil {
+		log.Printf("Failed to reward reaction for user %d: %v\n", msg.UserID, err)
+	}
+}
+
+// revokeReaction takes back the points a removed reaction earned the message author.
+func (app *application) revokeReaction(ctx context.Context, msg *database.Message, reactorID int64, key string) {
+	reaction, err := app.models.Reactions.Revoke(ctx, msg.ChatID, msg.MessageID, reactorID, key)
+	if err != nil {
+		log.Printf("Failed to remove reaction: %v\n", err)
+		return
+	}
+	if reaction == nil {
+		return
+	}
+
+	_, err = app.debit(ctx, reaction.ChatID, reaction.AuthorID, reaction.Points, pointSources[8])
+	if err != nil {
+		log.Printf("Failed to revoke reaction for user %d: %v\n", reaction.AuthorID, err)
+	}
+}
+
+// reactionKeys returns the set of reactions as the keys stored in the reactions table.
+func reactionKeys(reactions []models.ReactionType) map[string]bool {
+	keys := make(map[string]bool, len(reactions))
+	for _, r := range reactions {
+		switch r.Type {
+		case models.ReactionTypeTypeEmoji:
+			keys[r.ReactionTypeEmoji.Emoji] = true
+		case models.ReactionTypeTypeCustomEmoji:
+			keys["custom:"+r.ReactionTypeCustomEmoji.CustomEmojiID] = true
+		case models.ReactionTypeTypePaid:
+			keys["paid"] = true
+		}
+	}
+	return keys
+}
+
+// reactionPoints returns the points a reaction is worth, falling back to
+// bot.reactions.default for emoji that are not configured.
+func reactionPoints(key string) float64 {
+	if viper.IsSet("bot.reactions.emoji." + key) {
+		return viper.GetFloat64("bot.reactions.emoji." + key)
+	}
+	return viper.GetFloat64("bot.reactions.default")
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+// reportButton is the callback data prefix of the buttons under reports,
+// followed by the report ID and the chosen action.
+const reportButton = "report:"
+
+// Outcomes of a report, stored as its status
+const (
+	reportDeleted   = "deleted"   // The message was deleted
+	reportWarned    = "warned"    // The author was warned
+	reportMuted     = "muted"     // The author was muted
+	reportDismissed = "dismissed" // Nothing was wrong
+)
+
+// validateReportConfig reports invalid bot.reports settings.
+func validateReportConfig() error {
+	if mute := viper.GetString("bot.reports.mute"); mute != "" {
+		d, err := parseDuration(mute)
+		if err == nil {
+			err = checkSanctionDuration(d)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid bot.reports.mute: %v", err)
+		}
+	}
+
+	if viper.GetFloat64("bot.reports.reward") < 0 {
+		return fmt.Errorf("bot.reports.reward must not be negative")
+	}
+
+	return nil
+}
+
+// report lets members report the replied-to message to the admins.
+// (e.g.): reply with "/report spam"
+func (app *application) report(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.Message
+	chatID := msg.Chat.ID
+	msgId := msg.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	reported := msg.ReplyToMessage
+	if reported == nil || reported.From == nil || reported.ForumTopicCreated != nil {
+		sendMessage(ctx, b, chatID, msgId, "Usage: Reply to the message to report with `/report [reason]`.", true, deleteCmd)
+		return
+	}
+
+	if reported.From.ID == msg.From.ID {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotReportSelf, true, deleteCmd)
+		return
+	}
+
+	admins, err := getAdmins(ctx, b, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	if reported.From.IsBot || isAdmin(reported.From.ID, admins) {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotReportAdmin, true, deleteCmd)
+		return
+	}
+
+	report := &database.Report{
+		ChatID:     chatID,
+		MessageID:  reported.ID,
+		UserID:     reported.From.ID,
+		ReporterID: msg.From.ID,
+		Reason:     strings.TrimSpace(strings.Replace(msg.Text, "/report", "", 1)),
+	}
+
+	stored, err := app.models.Reports.Insert(ctx, report)
+	if err != nil {
+		log.Printf("Failed to store report: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+	if !stored {
+		sendMessage(ctx, b, chatID, msgId, ErrAlreadyReported, true, deleteCmd)
+		return
+	}
+
+	log.Printf("User %d reported message %d of user %d in chat %d: %s\n", report.ReporterID, report.MessageID, report.UserID, chatID, report.Reason)
+
+	var notice strings.Builder
+	notice.WriteString(fmt.Sprintf("🚩 *Report #%d:* %s reported a message of %s.\n", report.ID, mention(msg.From.ID, msg.From), mention(reported.From.ID, reported.From)))
+	if report.Reason != "" {
+		notice.WriteString(fmt.Sprintf("📝 Reason: %s\n", escapeMarkdown(report.Reason)))
+	}
+
+	// Mentions notify the admins even if they muted the chat
+	if viper.GetBool("bot.reports.mentionAdmins") {
+		var mentions []string
+		for _, admin := range admins {
+			if user := memberUser(admin); user != nil && !user.IsBot {
+				mentions = append(mentions, mention(user.ID, user))
+			}
+		}
+		notice.WriteString("👮 " + strings.Join(mentions, " ") + "\n")
+	}
+
+	data := reportButton + strconv.FormatInt(report.ID, 10) + ":"
+	markup := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "🗑 Delete", CallbackData: data + reportDeleted},
+				{Text: "⚠️ Warn", CallbackData: data + reportWarned},
+			},
+			{
+				{Text: "🔇 Mute", CallbackData: data + reportMuted},
+				{Text: "✖️ Dismiss", CallbackData: data + reportDismissed},
+			},
+		},
+	}
+
+	// Reply to the reported message so admins can see it right away
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          chatID,
+		Text:            notice.String(),
+		ParseMode:       models.ParseModeMarkdownV1,
+		ReplyMarkup:     markup,
+		MessageThreadID: threadID(msg),
+		ReplyParameters: &models.ReplyParameters{MessageID: reported.ID, AllowSendingWithoutReply: true},
+	})
+	if err != nil {
+		log.Printf("Failed to send report: %v\n", err)
+		// Without the notice admins could never handle the report, and the
+		// message could not be reported again
+		if err := app.models.Reports.Delete(ctx, chatID, report.ID); err != nil {
+			log.Printf("Failed to delete report %d: %v\n", report.ID, err)
+		}
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	app.notifySubscribers(ctx, b, msg.Chat, report, admins)
+
+	// The report itself stays hidden from the reported member
+	if deleteCmd {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: chatID, MessageID: msgId})
+	}
+}
+
+// notifySubscribers sends a new report to the admins who asked for reports in
+// private messages with /reports on. Members who are no longer admins are skipped.
+func (app *application) notifySubscribers(ctx context.Context, b *bot.Bot, chat models.Chat, report *database.Report, admins []models.ChatMember) {
+	subscribers, err := app.models.Reports.Subscribers(ctx, chat.ID)
+	if err != nil {
+		log.Printf("Failed to fetch report subscribers: %v\n", err)
+		return
+	}
+
+	text := fmt.Sprintf("🚩 New report #%d in *%s*: [open the message](%s)", report.ID, escapeMarkdown(chat.Title), messageLink(chat, report.MessageID))
+	if report.Reason != "" {
+		text += fmt.Sprintf("\n📝 Reason: %s", escapeMarkdown(report.Reason))
+	}
+
+	for _, adminID := range subscribers {
+		if !isAdmin(adminID, admins) {
+			continue
+		}
+
+		// Telegram only delivers to admins who started a chat with the bot
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    adminID,
+			Text:      text,
+			ParseMode: models.ParseModeMarkdownV1,
+		})
+		if err != nil {
+			log.Printf("Failed to send report to admin %d: %v\n", adminID, err)
+		}
+	}
+}
+
+// messageLink returns a link to a message of a public or private supergroup.
+func messageLink(chat models.Chat, messageID int) string {
+	if chat.Username != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", chat.Username, messageID)
+	}
+
+	// Private supergroup IDs are prefixed with -100
+	id := strings.TrimPrefix(strconv.FormatInt(chat.ID, 10), "-100")
+	return fmt.Sprintf("https://t.me/c/%s/%d", id, messageID)
+}
+
+// handleReport applies the action an admin chose under a report, and rewards
+// the reporter with bot.reports.reward points unless the report was dismissed.
+func (app *application) handleReport(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	notice := query.Message.Message
+	chatID := notice.Chat.ID
+	adminID := query.From.ID
+
+	idPart, action, _ := strings.Cut(strings.TrimPrefix(query.Data, reportButton), ":")
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		answerCallback(ctx, b, query, "Unknown report.")
+		return
+	}
+
+	switch action {
+	case reportDeleted, reportWarned, reportMuted, reportDismissed:
+	default:
+		answerCallback(ctx, b, query, "Unknown action.")
+		return
+	}
+
+	report, err := app.models.Reports.Get(ctx, chatID, id)
+	if err != nil || report == nil {
+		answerCallback(ctx, b, query, "Unknown report.")
+		return
+	}
+
+	// Only the first admin to press a button handles the report
+	resolved, err := app.models.Reports.Resolve(ctx, chatID, id, action, adminID)
+	if err != nil {
+		log.Printf("Failed to resolve report: %v\n", err)
+		answerCallback(ctx, b, query, "Something went wrong, please try again.")
+		return
+	}
+	if !resolved {
+		answerCallback(ctx, b, query, "This report was already handled.")
+		return
+	}
+
+	var user *models.User
+	if member := chatMember(ctx, b, chatID, report.UserID); member != nil {
+		user = memberUser(*member)
+	}
+
+	var outcome string
+	switch action {
+	case reportDeleted:
+		_, err = b.DeleteMessage(ctx, &bot.DeleteMessageParams{ChatID: chatID, MessageID: report.MessageID})
+		outcome = "🗑 The message was deleted"
+	case reportWarned:
+		reason := report.Reason
+		if reason == "" {
+			reason = "reported message"
+		}
+		var warning string
+		warning, err = app.addWarn(ctx, b, chatID, report.UserID, adminID, reason, user)
+		if err == nil {
+			sendNotice(ctx, b, chatID, warning, nil)
+		}
+		outcome = "⚠️ The author was warned"
+	case reportMuted:
+		d, _ := parseDuration(viper.GetString("bot.reports.mute"))
+		err = applySanction(ctx, b, chatID, report.UserID, sanctionMute, d)
+		outcome = formatSanction(sanctionMute, d)
+	case reportDismissed:
+		outcome = "✖️ Dismissed"
+	}
+	if err != nil {
+		log.Printf("Failed to apply report %d action %s: %v\n", id, action, err)
+		outcome = fmt.Sprintf("❗ Could not apply %q, check my admin rights", action)
+	}
+
+	log.Printf("Report %d in chat %d handled by %d: %s\n", id, chatID, adminID, action)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "report",
+		ActorID:  adminID,
+		Actor:    &query.From,
+		TargetID: report.UserID,
+		Target:   user,
+		Reason:   report.Reason,
+		Details:  fmt.Sprintf("Report #%d by %d: %s", id, report.ReporterID, action),
+	})
+
+	text := fmt.Sprintf("🚩 *Report #%d* on %s: %s by %s.", id, mention(report.UserID, user), outcome, mention(adminID, &query.From))
+
+	if reward := viper.GetFloat64("bot.reports.reward"); reward > 0 && action != reportDismissed && err == nil {
+		if err := app.credit(ctx, chatID, report.ReporterID, reward, pointSources[14]); err != nil {
+			log.Printf("Failed to reward reporter: %v\n", err)
+		} else {
+			text += fmt.Sprintf("\n🎁 The reporter earned %.2f points.", reward)
+		}
+	}
+
+	// Replace the report so its buttons can't be pressed again
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: notice.ID,
+		Text:      text,
+		ParseMode: models.ParseModeMarkdownV1,
+	})
+	if err != nil {
+		log.Printf("Failed to update report message: %v\n", err)
+	}
+
+	answerCallback(ctx, b, query, "Report handled.")
+}
+
+// reportSubscription lets an admin get the new reports of the chat in private messages.
+// (e.g.): "/reports on" || "/reports off"
+func (app *application) reportSubscription(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+	adminID := update.Message.From.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	arg := strings.TrimSpace(strings.Replace(update.Message.Text, "/reports", "", 1))
+	if arg != "on" && arg != "off" {
+		sendMessage(ctx, b, chatID, msgId, "Usage: `/reports on` or `/reports off`.", true, deleteCmd)
+		return
+	}
+
+	err := app.models.Reports.Subscribe(ctx, chatID, adminID, arg == "on")
+	if err != nil {
+		log.Printf("Failed to change report subscription: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("Admin %d turned report messages %s in chat %d\n", adminID, arg, chatID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  "reports " + arg,
+		ActorID: update.Message.From.ID,
+		Actor:   update.Message.From,
+	})
+
+	msg := "🔕 You will no longer get reports in private messages."
+	if arg == "on" {
+		msg = "🔔 You will get new reports in private messages. Start a chat with me first, or Telegram won't deliver them."
+	}
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
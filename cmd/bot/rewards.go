@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/spf13/viper"
+)
+
+// messageKind is a rewardable kind of message. Its points are read from
+// bot.point.<key>, except for text which is scored separately.
+type messageKind struct {
+	key   string
+	match func(msg *models.Message) bool
+}
+
+// messageKinds lists every kind of content a message can carry.
+// The first matching kind decides the points, so kinds that Telegram sends
+// alongside another one (an animation also has a document, a venue also has
+// a location) come first.
+var messageKinds = []messageKind{
+	{"text", func(msg *models.Message) bool { return msg.Text != "" }},
+	{"animation", func(msg *models.Message) bool { return msg.Animation != nil }},
+	{"audio", func(msg *models.Message) bool { return msg.Audio != nil }},
+	{"document", func(msg *models.Message) bool { return msg.Document != nil }},
+	{"paidMedia", func(msg *models.Message) bool { return msg.PaidMedia != nil }},
+	{"photo", func(msg *models.Message) bool { return len(msg.Photo) > 0 }},
+	{"sticker", func(msg *models.Message) bool { return msg.Sticker != nil }},
+	{"story", func(msg *models.Message) bool { return msg.Story != nil }},
+	{"video", func(msg *models.Message) bool { return msg.Video != nil }},
+	{"videoNote", func(msg *models.Message) bool { return msg.VideoNote != nil }},
+	{"voice", func(msg *models.Message) bool { return msg.Voice != nil }},
+	{"contact", func(msg *models.Message) bool { return msg.Contact != nil }},
+	{"dice", func(msg *models.Message) bool { return msg.Dice != nil }},
+	{"game", func(msg *models.Message) bool { return msg.Game != nil }},
+	{"poll", func(msg *models.Message) bool { return msg.Poll != nil }},
+	{"venue", func(msg *models.Message) bool { return msg.Venue != nil }},
+	{"location", func(msg *models.Message) bool { return msg.Location != nil }},
+}
+
+// pointModifiers are the bot.point keys that adjust the points of a kind
+// instead of being a kind themselves.
+//
+// - caption.bonus: added to media whose caption has at least caption.minLength characters.
+// - forwarded: multiplier for forwarded messages (unset keeps the full points).
+var pointModifiers = map[string][]string{
+	"caption":   {"bonus", "minLength"},
+	"forwarded": nil,
+}
+
+// textKeys are the keys allowed under bot.point.text.
+var textKeys = []string{"min", "max", "lengthTiers", "uniqueWords", "emojiOnly", "linkOnly", "duplicate"}
+
+// kindOf returns the kind of content msg carries, or nil for service messages.
+func kindOf(msg *models.Message) *messageKind {
+	for i := range messageKinds {
+		if messageKinds[i].match(msg) {
+			return &messageKinds[i]
+		}
+	}
+	return nil
+}
+
+// validatePointConfig reports misspelled or unknown keys under bot.point and
+// an invalid text range, so mistakes are caught at startup instead of
+// silently earning 0 points.
+func validatePointConfig() error {
+	var known []string
+	for _, kind := range messageKinds {
+		known = append(known, kind.key)
+	}
+	for key := range pointModifiers {
+		known = append(known, key)
+	}
+
+	var problems []string
+
+	problems = append(problems, unknownKeys("bot.point", known)...)
+	problems = append(problems, unknownKeys("bot.point.text", textKeys)...)
+	problems = append(problems, unknownKeys("bot.point.caption", pointModifiers["caption"])...)
+
+	if viper.IsSet("bot.point.text") {
+		min := viper.GetInt("bot.point.text.min")
+		max := viper.GetInt("bot.point.text.max")
+		if max <= min {
+			problems = append(problems, fmt.Sprintf("bot.point.text.max (%d) must be greater than bot.point.text.min (%d)", max, min))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid point config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// unknownKeys returns a problem for every key under section that is not in known.
+// Viper lower-cases keys, so the comparison is case-insensitive.
+func unknownKeys(section string, known []string) []string {
+	if !viper.IsSet(section) {
+		return nil
+	}
+
+	var keys []string
+	for key := range viper.GetStringMap(section) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		if contains(known, key) {
+			continue
+		}
+
+		problem := fmt.Sprintf("unknown key %s.%s", section, key)
+		if suggestion := closestKey(key, known); suggestion != "" {
+			problem += fmt.Sprintf(" (did you mean %s?)", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// contains reports whether key is in known, ignoring case.
+func contains(known []string, key string) bool {
+	for _, k := range known {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey returns the known key with the smallest edit distance to key,
+// if it is close enough to be a likely typo.
+func closestKey(key string, known []string) string {
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := editDistance(strings.ToLower(k), key); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/viper"
+)
+
+// linkPattern matches URLs and bare Telegram/www links in a message.
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|t\.me/)\S+`)
+
+// lengthTier gives messages of at least MinLength characters a point multiplier.
+type lengthTier struct {
+	MinLength  int     `mapstructure:"minLength"`
+	Multiplier float64 `mapstructure:"multiplier"`
+}
+
+// textRules score a text message. Each rule returns a multiplier that is
+// applied to the random text points; 1 leaves the points unchanged.
+// recent holds the user's previous messages in the chat, newest last.
+var textRules = []func(text string, recent []string) float64{
+	lengthRule,
+	uniqueWordsRule,
+	emojiOnlyRule,
+	linkOnlyRule,
+	duplicateRule,
+}
+
+// scoreText runs every text rule and returns the combined multiplier.
+func scoreText(text string, recent []string) float64 {
+	score := 1.0
+	for _, rule := range textRules {
+		score *= rule(text, recent)
+		if score == 0 {
+			break
+		}
+	}
+	return score
+}
+
+// lengthRule applies the multiplier of the highest tier in
+// bot.point.text.lengthTiers that the message length reaches.
+func lengthRule(text string, _ []string) float64 {
+	var tiers []lengthTier
+	if err := viper.UnmarshalKey("bot.point.text.lengthTiers", &tiers); err != nil || len(tiers) == 0 {
+		return 1
+	}
+
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinLength < tiers[j].MinLength })
+
+	length := len([]rune(strings.TrimSpace(text)))
+	multiplier := 1.0
+	for _, tier := range tiers {
+		if length >= tier.MinLength {
+			multiplier = tier.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// uniqueWordsRule applies bot.point.text.uniqueWords.multiplier to messages
+// with fewer than bot.point.text.uniqueWords.min distinct words.
+func uniqueWordsRule(text string, _ []string) float64 {
+	minWords := viper.GetInt("bot.point.text.uniqueWords.min")
+	if minWords <= 0 {
+		return 1
+	}
+
+	if len(uniqueWords(text)) < minWords {
+		return viper.GetFloat64("bot.point.text.uniqueWords.multiplier")
+	}
+	return 1
+}
+
+// emojiOnlyRule applies bot.point.text.emojiOnly to messages made of emoji only.
+func emojiOnlyRule(text string, _ []string) float64 {
+	if !viper.IsSet("bot.point.text.emojiOnly") || !isEmojiOnly(text) {
+		return 1
+	}
+	return viper.GetFloat64("bot.point.text.emojiOnly")
+}
+
+// linkOnlyRule applies bot.point.text.linkOnly to messages that contain
+// nothing but links.
+func linkOnlyRule(text string, _ []string) float64 {
+	if !viper.IsSet("bot.point.text.linkOnly") || !isLinkOnly(text) {
+		return 1
+	}
+	return viper.GetFloat64("bot.point.text.linkOnly")
+}
+
+// duplicateRule applies bot.point.text.duplicate.multiplier when the message is
+// at least bot.point.text.duplicate.similarity similar to one of the user's
+// recent messages.
+func duplicateRule(text string, recent []string) float64 {
+	threshold := viper.GetFloat64("bot.point.text.duplicate.similarity")
+	if threshold <= 0 {
+		return 1
+	}
+
+	for _, prev := range recent {
+		if similarity(text, prev) >= threshold {
+			return viper.GetFloat64("bot.point.text.duplicate.multiplier")
+		}
+	}
+	return 1
+}
+
+// uniqueWords returns the distinct lower-cased words of text.
+func uniqueWords(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+// isEmojiOnly reports whether text has at least one emoji and no letters or digits.
+func isEmojiOnly(text string) bool {
+	hasEmoji := false
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return false
+		case unicode.Is(unicode.So, r):
+			hasEmoji = true
+		}
+	}
+	return hasEmoji
+}
+
+// isLinkOnly reports whether text has at least one link and no other words.
+func isLinkOnly(text string) bool {
+	if !linkPattern.MatchString(text) {
+		return false
+	}
+	return len(uniqueWords(linkPattern.ReplaceAllString(text, ""))) == 0
+}
+
+// similarity returns the Jaccard similarity of the character trigrams of a
+// and b, from 0 (nothing in common) to 1 (identical after normalisation).
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		if normalize(a) == normalize(b) {
+			return 1
+		}
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams returns the set of three-character sequences of the normalised text.
+func trigrams(text string) map[string]struct{} {
+	runes := []rune(normalize(text))
+	set := make(map[string]struct{})
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// normalize lower-cases text and collapses every run of whitespace into one space.
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// useScoringConfig loads the text rules of the example config for one test.
+func useScoringConfig(t *testing.T) {
+	t.Helper()
+
+	viper.Set("bot.point.text.lengthTiers", []map[string]any{
+		{"minLength": 0, "multiplier": 0.2},
+		{"minLength": 15, "multiplier": 0.6},
+		{"minLength": 40, "multiplier": 1},
+	})
+	viper.Set("bot.point.text.uniqueWords.min", 3)
+	viper.Set("bot.point.text.uniqueWords.multiplier", 0.5)
+	viper.Set("bot.point.text.emojiOnly", 0.1)
+	viper.Set("bot.point.text.linkOnly", 0.1)
+	viper.Set("bot.point.text.duplicate.similarity", 0.8)
+	viper.Set("bot.point.text.duplicate.multiplier", 0)
+
+	t.Cleanup(viper.Reset)
+}
+
+func TestLengthRule(t *testing.T) {
+	useScoringConfig(t)
+
+	tests := []struct {
+		name string
+		text string
+		want float64
+	}{
+		{"empty", "", 0.2},
+		{"short", "hello", 0.2},
+		{"first tier boundary", "123456789012345", 0.6},
+		{"surrounding spaces are ignored", "   hello   ", 0.2},
+		{"runes not bytes", "ääääääääääääää", 0.2},
+		{"highest tier", "this message is long enough to reach the last tier", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lengthRule(tt.text, nil); got != tt.want {
+				t.Errorf("lengthRule(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLengthRuleWithoutTiers(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	if got := lengthRule("hi", nil); got != 1 {
+		t.Errorf("lengthRule without tiers = %v, want 1", got)
+	}
+}
+
+func TestUniqueWordsRule(t *testing.T) {
+	useScoringConfig(t)
+
+	tests := []struct {
+		name string
+		text string
+		want float64
+	}{
+		{"enough words", "one two three", 1},
+		{"repeated word", "spam spam spam spam", 0.5},
+		{"case insensitive", "Hi hi HI there", 0.5},
+		{"punctuation is not a word", "hi, there!!!", 0.5},
+		{"digits are words", "1 2 3", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueWordsRule(tt.text, nil); got != tt.want {
+				t.Errorf("uniqueWordsRule(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmojiOnlyRule(t *testing.T) {
+	useScoringConfig(t)
+
+	tests := []struct {
+		name string
+		text string
+		want float64
+	}{
+		{"single emoji", "🔥", 0.1},
+		{"emoji with spaces", "👍 🔥 ❤", 0.1},
+		{"emoji and text", "nice 🔥", 1},
+		{"emoji and digits", "🔥 100", 1},
+		{"punctuation only", "!!!", 1},
+		{"plain text", "hello", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := emojiOnlyRule(tt.text, nil); got != tt.want {
+				t.Errorf("emojiOnlyRule(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkOnlyRule(t *testing.T) {
+	useScoringConfig(t)
+
+	tests := []struct {
+		name string
+		text string
+		want float64
+	}{
+		{"url", "https://example.com/page", 0.1},
+		{"several links", "www.example.com t.me/somechat", 0.1},
+		{"link with punctuation", "https://example.com !", 0.1},
+		{"link with text", "look at https://example.com", 1},
+		{"no link", "just words", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkOnlyRule(tt.text, nil); got != tt.want {
+				t.Errorf("linkOnlyRule(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateRule(t *testing.T) {
+	useScoringConfig(t)
+
+	tests := []struct {
+		name   string
+		text   string
+		recent []string
+		want   float64
+	}{
+		{"no history", "hello everyone", nil, 1},
+		{"exact repeat", "hello everyone", []string{"hello everyone"}, 0},
+		{"case and spacing", "Hello   Everyone", []string{"hello everyone"}, 0},
+		{"older repeat", "hello everyone", []string{"hello everyone", "something else"}, 0},
+		{"different message", "hello everyone", []string{"what a game last night"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicateRule(tt.text, tt.recent); got != tt.want {
+				t.Errorf("duplicateRule(%q, %q) = %v, want %v", tt.text, tt.recent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateRuleDisabled(t *testing.T) {
+	useScoringConfig(t)
+	viper.Set("bot.point.text.duplicate.similarity", 0)
+
+	if got := duplicateRule("hello", []string{"hello"}); got != 1 {
+		t.Errorf("duplicateRule with similarity 0 = %v, want 1", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "hello world", "hello world", 1},
+		{"normalised", "Hello  World", "hello world", 1},
+		{"nothing shared", "abcdef", "uvwxyz", 0},
+		// "abcd" has abc, bcd and "abce" has abc, bce: 1 shared of 3
+		{"one shared trigram", "abcd", "abce", 1.0 / 3},
+		{"too short and equal", "hi", "HI", 1},
+		{"too short and different", "hi", "yo", 0},
+		{"one too short", "hi", "hello", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := similarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if rev := similarity(tt.b, tt.a); math.Abs(rev-got) > 1e-9 {
+				t.Errorf("similarity is not symmetric: %v and %v", got, rev)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/spf13/viper"
+)
+
+// Sender policies for messages sent on behalf of a chat (bot.senders.*)
+const (
+	senderSkip = "skip" // The message earns nothing
+	senderChat = "chat" // Points go to a chat-level account keyed by the sender chat ID
+)
+
+// senderID returns the ID that points for msg are credited to, or false if
+// the sender must not earn points at all.
+//
+// Messages from other bots never earn. Messages sent as a chat carry that chat
+// in sender_chat and a shared service user in from, so they are handled by
+// bot.senders.anonymousAdmins (sent as the group itself) and
+// bot.senders.channels (sent as, or automatically forwarded from, a channel).
+func senderID(msg *models.Message) (int64, bool) {
+	if msg.SenderChat != nil {
+		policy := viper.GetString("bot.senders.channels")
+		if msg.SenderChat.ID == msg.Chat.ID {
+			policy = viper.GetString("bot.senders.anonymousAdmins")
+		}
+
+		if policy == senderChat {
+			return msg.SenderChat.ID, true
+		}
+		return 0, false
+	}
+
+	if msg.From == nil || msg.From.IsBot {
+		return 0, false
+	}
+
+	return msg.From.ID, true
+}
+
+// validateSenderConfig reports unknown bot.senders policies.
+func validateSenderConfig() error {
+	for _, key := range []string{"bot.senders.anonymousAdmins", "bot.senders.channels"} {
+		switch policy := viper.GetString(key); policy {
+		case "", senderSkip, senderChat:
+		default:
+			return fmt.Errorf("invalid %s %q, use %q or %q", key, policy, senderSkip, senderChat)
+		}
+	}
+	return nil
+}
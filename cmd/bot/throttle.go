@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// throttleSweepInterval is how often idle entries are dropped from the throttle.
+	throttleSweepInterval = 10 * time.Minute
+
+	// recentTextTTL is how long a user's recent messages are kept for duplicate
+	// detection after they stop chatting.
+	recentTextTTL = time.Hour
+)
+
+// earningSources are the point sources that come from sending (or editing) messages.
+// Only these count towards the hourly and daily earning caps.
+var earningSources = []string{pointSources[1], pointSources[2], pointSources[4], pointSources[9], pointSources[11]}
+
+// chatUser identifies a user within a specific chat.
+type chatUser struct {
+	chatID int64
+	userID int64
+}
+
+// earnState is the short-lived earning state of a single user in a chat.
+type earnState struct {
+	lastReward time.Time // When the user was last rewarded for a message
+	burstStart time.Time // When the current burst of messages started
+	burstCount int       // Messages sent since burstStart
+	lastSeen   time.Time // When the user last sent a message
+	recent     []string  // The user's latest text messages, oldest first
+}
+
+// throttle enforces the cooldown between rewarded messages and the diminishing
+// returns for bursts, and remembers recent messages for duplicate detection.
+// The state is kept in memory since it is short-lived; the hourly and daily
+// caps are computed from the point history instead.
+type throttle struct {
+	mu        sync.Mutex
+	users     map[chatUser]*earnState
+	lastSweep time.Time
+}
+
+func newThrottle() *throttle {
+	return &throttle{
+		users:     make(map[chatUser]*earnState),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow reports whether a message sent by the user at now may be rewarded,
+// along with the multiplier to apply to its points.
+//
+// Configured under bot.throttle:
+// - cooldown: minimum seconds between two rewarded messages.
+// - burst.window: seconds a burst of messages lasts.
+// - burst.size: messages within a burst that earn the full amount.
+// - burst.decay: every further message in the burst earns this fraction of the previous one.
+func (t *throttle) allow(chatID, userID int64, now time.Time) (float64, bool) {
+	cooldown := time.Duration(viper.GetInt("bot.throttle.cooldown")) * time.Second
+	window := time.Duration(viper.GetInt("bot.throttle.burst.window")) * time.Second
+	size := viper.GetInt("bot.throttle.burst.size")
+	decay := viper.GetFloat64("bot.throttle.burst.decay")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state(chatID, userID, now)
+
+	// Every message counts towards the burst, rewarded or not
+	if now.Sub(state.burstStart) > window {
+		state.burstStart = now
+		state.burstCount = 0
+	}
+	state.burstCount++
+
+	if cooldown > 0 && now.Sub(state.lastReward) < cooldown {
+		return 0, false
+	}
+	state.lastReward = now
+
+	factor := 1.0
+	if window > 0 && size > 0 && decay > 0 && state.burstCount > size {
+		factor = math.Pow(decay, float64(state.burstCount-size))
+	}
+
+	return factor, true
+}
+
+// remember records text as the user's latest message and returns the messages
+// they sent before it, oldest first. At most bot.point.text.duplicate.history
+// messages are kept per user.
+func (t *throttle) remember(chatID, userID int64, text string, now time.Time) []string {
+	keep := viper.GetInt("bot.point.text.duplicate.history")
+	if keep <= 0 || text == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state(chatID, userID, now)
+	recent := slices.Clone(state.recent)
+
+	state.recent = append(state.recent, text)
+	if len(state.recent) > keep {
+		state.recent = state.recent[len(state.recent)-keep:]
+	}
+
+	return recent
+}
+
+// state returns the earning state of the user, creating it if needed.
+// It must be called with t.mu held.
+func (t *throttle) state(chatID, userID int64, now time.Time) *earnState {
+	t.sweep(now)
+
+	key := chatUser{chatID: chatID, userID: userID}
+	state, ok := t.users[key]
+	if !ok {
+		state = &earnState{}
+		t.users[key] = state
+	}
+	state.lastSeen = now
+
+	return state
+}
+
+// sweep drops users that have not sent a message for recentTextTTL, which is
+// longer than any cooldown or burst window in practice.
+// It runs at most once every throttleSweepInterval and must be called with t.mu held.
+func (t *throttle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < throttleSweepInterval {
+		return
+	}
+	t.lastSweep = now
+
+	for key, state := range t.users {
+		if now.Sub(state.lastSeen) > recentTextTTL {
+			delete(t.users, key)
+		}
+	}
+}
+
+// remainingEarnings returns how many more points a user may earn from messages
+// in the current hour and today, according to bot.throttle.hourlyCap and
+// bot.throttle.dailyCap. An unset cap is reported as +Inf.
+func (app *application) remainingEarnings(ctx context.Context, chatID, userID int64, now time.Time) (hour, day float64, err error) {
+	hour, day = math.Inf(1), math.Inf(1)
+
+	if hourlyCap := viper.GetFloat64("bot.throttle.hourlyCap"); hourlyCap > 0 {
+		earned, err := app.models.Points.Earned(ctx, chatID, userID, now.Add(-time.Hour), earningSources)
+		if err != nil {
+			return 0, 0, err
+		}
+		hour = max(hourlyCap-earned, 0)
+	}
+
+	if dailyCap := viper.GetFloat64("bot.throttle.dailyCap"); dailyCap > 0 {
+		today := now.UTC().Truncate(24 * time.Hour)
+		earned, err := app.models.Points.Earned(ctx, chatID, userID, today, earningSources)
+		if err != nil {
+			return 0, 0, err
+		}
+		day = max(dailyCap-earned, 0)
+	}
+
+	return hour, day, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/spf13/viper"
+)
+
+// maxTopicMultiplier is the highest point multiplier a topic can have.
+const maxTopicMultiplier = 10
+
+// topicKey is the context key holding the forum topic of the update being handled.
+type topicKey struct{}
+
+// topicMiddleware remembers the forum topic the update's message, or the
+// message of the pressed button, was sent in, so every reply sent through
+// sendMessage lands in the same topic.
+func topicMiddleware(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		msg := update.Message
+		if query := update.CallbackQuery; query != nil {
+			msg = query.Message.Message
+		}
+		if msg != nil {
+			if thread := threadID(msg); thread != 0 {
+				ctx = context.WithValue(ctx, topicKey{}, thread)
+			}
+		}
+		next(ctx, b, update)
+	}
+}
+
+// threadID returns the forum topic msg was sent in, or 0 for the General topic
+// and chats without topics. Replies in regular supergroups also carry a
+// thread ID, so it is only used for topic messages.
+func threadID(msg *models.Message) int {
+	if !msg.IsTopicMessage {
+		return 0
+	}
+	return msg.MessageThreadID
+}
+
+// topicMultiplier returns the point multiplier of a forum topic, 1 if none was set.
+func (app *application) topicMultiplier(ctx context.Context, chatID int64, threadID int) (float64, error) {
+	topic, err := app.models.Topics.Get(ctx, chatID, threadID)
+	if err != nil {
+		return 0, err
+	}
+	if topic == nil {
+		return 1, nil
+	}
+	return topic.Multiplier, nil
+}
+
+// topicPoints shows or sets the point multiplier of the topic the command is sent in
+// (e.g.): "/topicpoints 1.5" || "/topicpoints 0" to stop earning in the topic
+func (app *application) topicPoints(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+	thread := threadID(update.Message)
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	arg := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/topicpoints"))
+
+	if arg == "" {
+		multiplier, err := app.topicMultiplier(ctx, chatID, thread)
+		if err != nil {
+			sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+			return
+		}
+
+		msg := fmt.Sprintf("📌 Messages in this topic earn *%.2fx* points.", multiplier)
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	multiplier, err := strconv.ParseFloat(arg, 64)
+	// ParseFloat accepts "NaN" and "Inf", which would break every score in the topic
+	if err != nil || math.IsNaN(multiplier) || math.IsInf(multiplier, 0) || multiplier < 0 || multiplier > maxTopicMultiplier {
+		sendMessage(ctx, b, chatID, msgId, ErrInvalidMultiplier, true, deleteCmd)
+		return
+	}
+
+	err = app.models.Topics.SetMultiplier(ctx, chatID, thread, multiplier)
+	if err != nil {
+		log.Printf("Failed to set topic multiplier: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("Topic %d multiplier in chat %d set to %.2f by %d\n", thread, chatID, multiplier, update.Message.From.ID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:  "topicpoints",
+		ActorID: update.Message.From.ID,
+		Actor:   update.Message.From,
+		Details: fmt.Sprintf("Topic %d: %.2fx points", thread, multiplier),
+	})
+
+	msg := fmt.Sprintf("✅ Messages in this topic now earn *%.2fx* points.", multiplier)
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
+
+// topicRanking shows the ranking of the forum topic the command is sent in.
+// The period defaults to monthly.
+func (app *application) topicRanking(ctx context.Context, b *bot.Bot, update *models.Update, period string) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	titles := map[string]string{
+		"daily":   "🏆 *Daily Topic Rankings*",
+		"weekly":  "🌟 *Weekly Topic Rankings*",
+		"monthly": "🎖 *Monthly Topic Rankings*",
+	}
+
+	if period == "" {
+		period = "monthly"
+	}
+
+	title, exists := titles[period]
+	if !exists {
+		msg := "Use `/rank topic daily`, `/rank topic weekly`, or `/rank topic monthly`."
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	rankingLimit := 20
+
+	points, err := app.models.Points.TopicRanking(ctx, chatID, threadID(update.Message), rankingLimit, period)
+	if err != nil {
+		log.Printf("Failed to fetch topic ranking: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrNoRankingsAvailable, true, deleteCmd)
+		return
+	}
+	if points == nil {
+		sendMessage(ctx, b, chatID, msgId, ErrNoRankingsAvailable, true, deleteCmd)
+		return
+	}
+
+	sendMessage(ctx, b, chatID, msgId, formatRankingMessage(title, points), true, deleteCmd)
+}
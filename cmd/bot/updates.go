@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// isChatMigration reports whether the update is the service message Telegram
+// sends to a group after it has been upgraded to a supergroup.
+func isChatMigration(update *models.Update) bool {
+	return update.Message != nil && update.Message.MigrateToChatID != 0
+}
+
+// migrateChat moves all balances, history, boosts, gifts, events and moderation settings from the old group
+// to the new supergroup ID so members keep their points after the upgrade.
+func (app *application) migrateChat(ctx context.Context, b *bot.Bot, update *models.Update) {
+	fromChatID := update.Message.Chat.ID
+	toChatID := update.Message.MigrateToChatID
+
+	cm, err := app.models.Chats.Migrate(ctx, fromChatID, toChatID)
+	if err != nil {
+		log.Printf("Failed to migrate chat %d to %d: %v\n", fromChatID, toChatID, err)
+		return
+	}
+
+	log.Printf("Migrated chat %d to %d: %d users (%d merged), %d point records, %d boosts, %d gifts, %d events, %d event schedules, %d members, %d settings, %d warns, %d filters, %d captchas, %d reports, %d audit records, %d engagements\n",
+		cm.FromChatID, cm.ToChatID, cm.Users, cm.Merged, cm.Points, cm.Boosts, cm.Gifts, cm.Events, cm.Schedules, cm.Members, cm.Settings, cm.Warns, cm.Filters, cm.Captchas, cm.Reports, cm.AuditLog, cm.Engagements)
+}
+
+// isEdit reports whether the update is an edited message.
+func isEdit(update *models.Update) bool {
+	return update.EditedMessage != nil
+}
+
+// editMessage re-scores an edited message and awards or revokes the difference
+// to what it earned before, so a placeholder can't be edited into a long text
+// for free and a rewarded text can't be emptied afterwards.
+func (app *application) editMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.EditedMessage
+	chatID := msg.Chat.ID
+
+	if msg.Chat.Type == models.ChatTypePrivate || msg.Chat.Type == models.ChatTypeChannel {
+		return
+	}
+
+	userID, ok := senderID(msg)
+	if !ok {
+		return
+	}
+
+	record, err := app.models.Messages.Get(ctx, chatID, msg.ID)
+	if err != nil {
+		log.Printf("Failed to fetch message %d: %v\n", msg.ID, err)
+		return
+	}
+
+	// Messages sent before the bot joined, already pruned, never scored
+	// because of the cooldown, or scored before scales were recorded are left alone
+	if record == nil || !record.Scored || record.Scale == nil {
+		return
+	}
+
+	// The edit is scored with the same throttle, topic, probation, duplicate,
+	// boost and event multipliers and the same random roll as the original message
+	base := calculatePoints(msg, nil, false)
+	scale := *record.Scale
+	delta := base*scale - record.Points
+
+	switch {
+	case delta > 0:
+		hourLeft, dayLeft, err := app.remainingEarnings(ctx, chatID, userID, time.Now())
+		if err != nil {
+			log.Printf("Failed to check earning caps: %v\n", err)
+			return
+		}
+
+		// Like a new message, the scale keeps only what the caps let through
+		if capped := max(min(delta, hourLeft, dayLeft), 0); capped < delta {
+			delta = capped
+			scale = (record.Points + delta) / base
+		}
+
+		if delta > 0 {
+			err = app.credit(ctx, chatID, userID, delta, pointSources[9])
+			if err != nil {
+				log.Printf("Failed to credit edited message: %v\n", err)
+				return
+			}
+		}
+	case delta < 0:
+		removed, err := app.debit(ctx, chatID, userID, -delta, pointSources[9])
+		if err != nil {
+			log.Printf("Failed to debit edited message: %v\n", err)
+			return
+		}
+		delta = -removed
+	}
+
+	if delta == 0 && scale == *record.Scale {
+		return
+	}
+
+	err = app.models.Messages.Score(ctx, chatID, msg.ID, record.Points+delta, scale)
+	if err != nil {
+		log.Printf("Failed to record message points: %v\n", err)
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/joybiswas007/modbot-tg/internal/database"
+	"github.com/spf13/viper"
+)
+
+// warnAction is a sanction applied when a member reaches a number of warnings,
+// configured as a list under bot.warns.actions.
+type warnAction struct {
+	Count    int    `mapstructure:"count"`    // Warnings that trigger the action
+	Action   string `mapstructure:"action"`   // "mute", "kick" or "ban"
+	Duration string `mapstructure:"duration"` // How long a mute or ban lasts, e.g. "1h" or "2d", empty for forever
+}
+
+// warnActions returns the configured escalation steps.
+func warnActions() []warnAction {
+	var actions []warnAction
+	if err := viper.UnmarshalKey("bot.warns.actions", &actions); err != nil {
+		return nil
+	}
+	return actions
+}
+
+// warnStep returns the escalation step for a member with count warnings: the
+// one with the highest count they reached. Warnings past the last step repeat it.
+func warnStep(actions []warnAction, count int) (warnAction, bool) {
+	var step warnAction
+	found := false
+	for _, action := range actions {
+		if action.Count <= count && (!found || action.Count > step.Count) {
+			step, found = action, true
+		}
+	}
+	return step, found
+}
+
+// validateWarnConfig reports invalid escalation steps and penalties under bot.warns.
+func validateWarnConfig() error {
+	var actions []warnAction
+	if err := viper.UnmarshalKey("bot.warns.actions", &actions); err != nil {
+		return fmt.Errorf("invalid bot.warns.actions: %v", err)
+	}
+
+	for i, action := range actions {
+		if action.Count <= 0 {
+			return fmt.Errorf("bot.warns.actions[%d]: count must be greater than 0", i)
+		}
+
+		switch action.Action {
+		case sanctionMute, sanctionKick, sanctionBan:
+		default:
+			return fmt.Errorf("bot.warns.actions[%d]: unknown action %q, use %q, %q or %q", i, action.Action, sanctionMute, sanctionKick, sanctionBan)
+		}
+
+		if action.Duration != "" {
+			d, err := parseDuration(action.Duration)
+			if err == nil {
+				err = checkSanctionDuration(d)
+			}
+			if err != nil {
+				return fmt.Errorf("bot.warns.actions[%d]: %v", i, err)
+			}
+		}
+	}
+
+	if viper.GetFloat64("bot.warns.penalty") < 0 {
+		return fmt.Errorf("bot.warns.penalty must not be negative")
+	}
+
+	return nil
+}
+
+// warn gives a member a warning, deducts bot.warns.penalty points and applies
+// the escalation step for their new number of warnings, if any.
+// (e.g.): "/warn userid spamming" || reply with "/warn spamming"
+func (app *application) warn(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+	adminID := update.Message.From.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/warn", "", 1)))
+	userID, rest, ok := commandTarget(update.Message, args)
+	if !ok {
+		msg := "Usage: Reply to a users message with `/warn [reason]` or use `/warn user_id [reason]`."
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+	reason := strings.Join(rest, " ")
+
+	if userID == adminID {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotWarnSelf, true, deleteCmd)
+		return
+	}
+
+	admins, err := getAdmins(ctx, b, chatID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	if isAdmin(userID, admins) {
+		sendMessage(ctx, b, chatID, msgId, ErrCannotWarnAdmin, true, deleteCmd)
+		return
+	}
+
+	user := lookupUser(ctx, b, update.Message, userID)
+	msg, err := app.addWarn(ctx, b, chatID, userID, adminID, reason, user)
+	if err != nil {
+		log.Printf("Failed to warn user: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "warn",
+		ActorID:  adminID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+		Target:   user,
+		Reason:   reason,
+	})
+
+	sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+}
+
+// addWarn stores a warning for a member and applies its consequences.
+// It returns the message announcing the warning.
+func (app *application) addWarn(ctx context.Context, b *bot.Bot, chatID, userID, adminID int64, reason string, user *models.User) (string, error) {
+	count, err := app.models.Warns.Insert(ctx, &database.Warn{
+		ChatID:  chatID,
+		UserID:  userID,
+		AdminID: adminID,
+		Reason:  reason,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("User %d warned in chat %d by %d (%d warns): %s\n", userID, chatID, adminID, count, reason)
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("⚠️ %s has been warned (%s).\n", mention(userID, user), formatWarnCount(count)))
+	if reason != "" {
+		msg.WriteString(fmt.Sprintf("📝 Reason: %s\n", escapeMarkdown(reason)))
+	}
+
+	msg.WriteString(app.warnConsequences(ctx, b, chatID, userID, count))
+
+	return msg.String(), nil
+}
+
+// warnConsequences deducts the warning penalty from a member who just reached count
+// warnings and applies the escalation step they reached (see warnStep). It
+// returns a line for every consequence, to be added to the warning message.
+func (app *application) warnConsequences(ctx context.Context, b *bot.Bot, chatID, userID int64, count int) string {
+	var msg strings.Builder
+
+	if penalty := viper.GetFloat64("bot.warns.penalty"); penalty > 0 {
+		removed, err := app.debit(ctx, chatID, userID, penalty, pointSources[6])
+		if err != nil {
+			log.Printf("Failed to deduct warn penalty: %v\n", err)
+		} else if removed > 0 {
+			msg.WriteString(fmt.Sprintf("➖ %.2f points deducted.\n", removed))
+		}
+	}
+
+	action, ok := warnStep(warnActions(), count)
+	if !ok {
+		return msg.String()
+	}
+
+	d, _ := parseDuration(action.Duration)
+	if err := applySanction(ctx, b, chatID, userID, action.Action, d); err != nil {
+		log.Printf("Failed to %s user %d after %d warns: %v\n", action.Action, userID, count, err)
+		msg.WriteString(fmt.Sprintf("❗ Could not %s the user, check my admin rights.\n", action.Action))
+		return msg.String()
+	}
+
+	log.Printf("User %d in chat %d reached %d warns: %s\n", userID, chatID, count, formatSanction(action.Action, d))
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   action.Action,
+		TargetID: userID,
+		Duration: d,
+		Reason:   fmt.Sprintf("reached %d warnings", count),
+	})
+	msg.WriteString(formatSanction(action.Action, d) + ".\n")
+
+	return msg.String()
+}
+
+// formatWarnCount shows the warnings of a member against the last escalation
+// step, e.g. "2/5", or just the count when no steps are configured.
+func formatWarnCount(count int) string {
+	limit := 0
+	for _, action := range warnActions() {
+		limit = max(limit, action.Count)
+	}
+
+	if limit == 0 {
+		return fmt.Sprintf("%d", count)
+	}
+	return fmt.Sprintf("%d/%d", count, limit)
+}
+
+// warns lists the warnings of the replied-to user, the given user ID or the sender.
+func (app *application) warns(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/warns", "", 1)))
+	userID, _, ok := commandTarget(update.Message, args)
+	if !ok {
+		if len(args) > 0 {
+			sendMessage(ctx, b, chatID, msgId, ErrInvalidUserID, true, deleteCmd)
+			return
+		}
+		userID = update.Message.From.ID
+	}
+
+	warns, err := app.models.Warns.List(ctx, chatID, userID)
+	if err != nil {
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	name := mention(userID, lookupUser(ctx, b, update.Message, userID))
+	if len(warns) == 0 {
+		sendMessage(ctx, b, chatID, msgId, fmt.Sprintf("✅ %s has no warnings.", name), true, deleteCmd)
+		return
+	}
+
+	sendMessage(ctx, b, chatID, msgId, formatWarns(name, warns), true, deleteCmd)
+}
+
+// formatWarns lists warnings with their date and reason.
+func formatWarns(name string, warns []database.Warn) string {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("⚠️ *Warnings of* %s (%s)\n\n", name, formatWarnCount(len(warns))))
+
+	for i, warn := range warns {
+		reason := warn.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		msg.WriteString(fmt.Sprintf("%d. 🕒 %s - %s\n", i+1, warn.CreatedAt.Format(time.DateTime), escapeMarkdown(reason)))
+	}
+
+	return msg.String()
+}
+
+// unwarn removes the latest warning of a member.
+// (e.g.): "/unwarn userid" || reply with "/unwarn"
+func (app *application) unwarn(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/unwarn", "", 1)))
+	userID, _, ok := commandTarget(update.Message, args)
+	if !ok {
+		msg := "Usage: Reply to a users message with `/unwarn` or use `/unwarn user_id`."
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	removed, err := app.models.Warns.RemoveLatest(ctx, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to remove warn: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	name := mention(userID, lookupUser(ctx, b, update.Message, userID))
+	if !removed {
+		sendMessage(ctx, b, chatID, msgId, fmt.Sprintf("✅ %s has no warnings.", name), true, deleteCmd)
+		return
+	}
+
+	log.Printf("Latest warn of user %d removed in chat %d by %d\n", userID, chatID, update.Message.From.ID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "unwarn",
+		ActorID:  update.Message.From.ID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+	})
+	sendMessage(ctx, b, chatID, msgId, fmt.Sprintf("↩️ Removed the latest warning of %s.", name), true, deleteCmd)
+}
+
+// resetWarns removes every warning of a member.
+// (e.g.): "/resetwarns userid" || reply with "/resetwarns"
+func (app *application) resetWarns(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	msgId := update.Message.ID
+
+	// deleteCmd determines if commands should be deleted after execution
+	deleteCmd := viper.GetBool("bot.deleteCommand")
+
+	args := strings.Fields(strings.TrimSpace(strings.Replace(update.Message.Text, "/resetwarns", "", 1)))
+	userID, _, ok := commandTarget(update.Message, args)
+	if !ok {
+		msg := "Usage: Reply to a users message with `/resetwarns` or use `/resetwarns user_id`."
+		sendMessage(ctx, b, chatID, msgId, msg, true, deleteCmd)
+		return
+	}
+
+	n, err := app.models.Warns.Reset(ctx, chatID, userID)
+	if err != nil {
+		log.Printf("Failed to reset warns: %v\n", err)
+		sendMessage(ctx, b, chatID, msgId, ErrUnknownError, true, deleteCmd)
+		return
+	}
+
+	log.Printf("%d warns of user %d reset in chat %d by %d\n", n, userID, chatID, update.Message.From.ID)
+	app.logAction(ctx, b, chatID, adminAction{
+		Action:   "resetwarns",
+		ActorID:  update.Message.From.ID,
+		Actor:    update.Message.From,
+		TargetID: userID,
+		Details:  fmt.Sprintf("%d warning(s) cleared", n),
+	})
+
+	name := mention(userID, lookupUser(ctx, b, update.Message, userID))
+	sendMessage(ctx, b, chatID, msgId, fmt.Sprintf("🧽 Cleared %d warning(s) of %s.", n, name), true, deleteCmd)
+}
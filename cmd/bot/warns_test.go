@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joybiswas007/modbot-tg/internal/database"
+)
+
+func TestWarnStep(t *testing.T) {
+	// Steps don't have to be sorted in the config
+	actions := []warnAction{
+		{Count: 5, Action: sanctionBan},
+		{Count: 3, Action: sanctionMute, Duration: "1h"},
+	}
+
+	tests := []struct {
+		count  int
+		want   string
+		wantOK bool
+	}{
+		{1, "", false},
+		{2, "", false},
+		{3, sanctionMute, true},
+		{4, sanctionMute, true},
+		{5, sanctionBan, true},
+		{8, sanctionBan, true},
+	}
+
+	for _, tt := range tests {
+		step, ok := warnStep(actions, tt.count)
+		if ok != tt.wantOK || step.Action != tt.want {
+			t.Errorf("warnStep(%d) = %q, %v, want %q, %v", tt.count, step.Action, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if _, ok := warnStep(nil, 10); ok {
+		t.Error("warnStep without steps found one")
+	}
+}
+
+func TestFormatWarnsEscapesReasons(t *testing.T) {
+	got := formatWarns("Ann", []database.Warn{{Reason: "spam_links"}, {}})
+
+	for _, want := range []string{"spam\\_links\n", "no reason given\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatWarns() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AuditModel handles operations related to the audit_log table.
+type AuditModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// AuditEntry is a privileged command, or a sanction the bot applied on its own.
+type AuditEntry struct {
+	ID        int64
+	ChatID    int64
+	ActorID   int64  // The admin, 0 when the bot acted on its own
+	TargetID  int64  // The member it was done to, 0 for chat settings
+	Action    string // e.g. "seize", "warn" or "filter add"
+	Params    string // JSON object of the amount, duration and details
+	Reason    string // Optional, empty when none was given
+	CreatedAt time.Time
+}
+
+// Insert stores an audit entry and sets its ID.
+func (a AuditModel) Insert(ctx context.Context, entry *AuditEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO audit_log(chat_id, actor_id, target_id, action, params, reason) VALUES(?, ?, ?, ?, ?, ?)`
+
+	res, err := a.DB.ExecContext(ctx, query, entry.ChatID, entry.ActorID, entry.TargetID, entry.Action, entry.Params, entry.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %v", err)
+	}
+
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+// List returns the latest entries of a chat, newest first. A targetID other
+// than 0 only returns the entries about that member, and a limit below 1
+// returns every entry.
+func (a AuditModel) List(ctx context.Context, chatID, targetID int64, limit int) ([]AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, actor_id, target_id, action, params, reason, created_at
+		  FROM audit_log WHERE chat_id = ? AND (? = 0 OR target_id = ?)
+		  ORDER BY id DESC LIMIT ?`
+
+	// sqlite treats a negative limit as no limit
+	if limit < 1 {
+		limit = -1
+	}
+
+	rows, err := a.DB.QueryContext(ctx, query, chatID, targetID, targetID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ChatID,
+			&entry.ActorID,
+			&entry.TargetID,
+			&entry.Action,
+			&entry.Params,
+			&entry.Reason,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
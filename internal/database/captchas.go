@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CaptchaModel handles operations related to the captchas table.
+type CaptchaModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Captcha is a join challenge a newcomer has not solved yet.
+type Captcha struct {
+	ChatID    int64
+	UserID    int64
+	MessageID int    // The challenge posted by the bot
+	Answer    string // Callback answer that solves the challenge
+	ExpiresAt time.Time
+
+	// Restriction holds the JSON permissions the newcomer was restricted to
+	// before the captcha, empty when they were not restricted.
+	Restriction     string
+	RestrictedUntil int64 // When that restriction ends as a Unix time, 0 for forever
+}
+
+// Insert stores a captcha, replacing any earlier one of the user in the chat.
+func (c CaptchaModel) Insert(ctx context.Context, captcha *Captcha) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO captchas(chat_id, user_id, message_id, answer, expires_at, restriction, restricted_until) VALUES(?, ?, ?, ?, ?, ?, ?)
+		  ON CONFLICT(chat_id, user_id) DO UPDATE SET
+			message_id = excluded.message_id,
+			answer = excluded.answer,
+			expires_at = excluded.expires_at,
+			restriction = excluded.restriction,
+			restricted_until = excluded.restricted_until`
+
+	_, err := c.DB.ExecContext(ctx, query, captcha.ChatID, captcha.UserID, captcha.MessageID, captcha.Answer,
+		captcha.ExpiresAt.UTC().Format(time.DateTime), captcha.Restriction, captcha.RestrictedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to insert captcha: %v", err)
+	}
+	return nil
+}
+
+// Get returns the pending captcha of a user, or nil if there is none.
+func (c CaptchaModel) Get(ctx context.Context, chatID, userID int64) (*Captcha, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, user_id, message_id, answer, expires_at, restriction, restricted_until
+		  FROM captchas WHERE chat_id = ? AND user_id = ?`
+
+	var captcha Captcha
+	err := c.DB.QueryRowContext(ctx, query, chatID, userID).Scan(
+		&captcha.ChatID,
+		&captcha.UserID,
+		&captcha.MessageID,
+		&captcha.Answer,
+		&captcha.ExpiresAt,
+		&captcha.Restriction,
+		&captcha.RestrictedUntil,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &captcha, nil
+}
+
+// Expired returns the captchas that expired at or before now.
+func (c CaptchaModel) Expired(ctx context.Context, now time.Time) ([]Captcha, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, user_id, message_id, answer, expires_at, restriction, restricted_until
+		  FROM captchas WHERE expires_at <= ?`
+
+	rows, err := c.DB.QueryContext(ctx, query, now.UTC().Format(time.DateTime))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query captchas: %v", err)
+	}
+	defer rows.Close()
+
+	var captchas []Captcha
+	for rows.Next() {
+		var captcha Captcha
+		err := rows.Scan(&captcha.ChatID, &captcha.UserID, &captcha.MessageID, &captcha.Answer, &captcha.ExpiresAt,
+			&captcha.Restriction, &captcha.RestrictedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		captchas = append(captchas, captcha)
+	}
+
+	return captchas, rows.Err()
+}
+
+// Delete removes the captcha of a user and reports whether there was one, so
+// a challenge can only be settled once.
+func (c CaptchaModel) Delete(ctx context.Context, chatID, userID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	res, err := c.DB.ExecContext(ctx, `DELETE FROM captchas WHERE chat_id = ? AND user_id = ?`, chatID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete captcha: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
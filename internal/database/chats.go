@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ChatModel handles operations that span every table of a chat.
+type ChatModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// ChatMigration reports how many rows of each table were moved to the new chat ID.
+type ChatMigration struct {
+	FromChatID        int64
+	ToChatID          int64
+	Users             int64 // Users moved as-is
+	Merged            int64 // Users that already existed in the new chat and got their balance merged
+	Points            int64
+	Boosts            int64
+	Gifts             int64
+	Events            int64
+	Schedules         int64
+	Members           int64
+	Settings          int64
+	Warns             int64
+	Filters           int64
+	Captchas          int64
+	Reports           int64
+	ReportSubscribers int64
+	AuditLog          int64
+	Engagements       int64
+}
+
+// Migrate moves every row that belongs to fromChatID over to toChatID in one transaction.
+// It is used when Telegram upgrades a group to a supergroup and the chat ID changes.
+// Users who already earned points in the new chat have their old balance added to it,
+// for other rows that already exist in the new chat the new ones win.
+func (c ChatModel) Migrate(ctx context.Context, fromChatID, toChatID int64) (*ChatMigration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin chat migration: %v", err)
+	}
+	defer tx.Rollback()
+
+	cm := &ChatMigration{FromChatID: fromChatID, ToChatID: toChatID}
+
+	// Merge users that already have a row in the new chat
+	merge := `UPDATE users SET
+			points = points + (SELECT o.points FROM users o WHERE o.chat_id = ? AND o.user_id = users.user_id),
+			updated_at = ?
+		  WHERE chat_id = ? AND user_id IN (SELECT user_id FROM users WHERE chat_id = ?)`
+	cm.Merged, err = execCount(ctx, tx, merge, fromChatID, time.Now(), toChatID, fromChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge users: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM users WHERE chat_id = ? AND user_id IN (SELECT user_id FROM users WHERE chat_id = ?)`, fromChatID, toChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove merged users: %v", err)
+	}
+
+	// Daily totals are keyed by chat, so they are merged the same way
+	daily := `INSERT INTO point_daily(chat_id, day, user_id, gained, lost)
+		  SELECT ?, day, user_id, gained, lost FROM point_daily WHERE chat_id = ?
+		  ON CONFLICT(chat_id, day, user_id) DO UPDATE SET gained = gained + excluded.gained, lost = lost + excluded.lost`
+	_, err = tx.ExecContext(ctx, daily, toChatID, fromChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge daily points: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM point_daily WHERE chat_id = ?`, fromChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove merged daily points: %v", err)
+	}
+
+	topicDaily := `INSERT INTO point_topic_daily(chat_id, thread_id, day, user_id, gained, lost)
+		       SELECT ?, thread_id, day, user_id, gained, lost FROM point_topic_daily WHERE chat_id = ?
+		       ON CONFLICT(chat_id, thread_id, day, user_id) DO UPDATE SET gained = gained + excluded.gained, lost = lost + excluded.lost`
+	_, err = tx.ExecContext(ctx, topicDaily, toChatID, fromChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge daily topic points: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM point_topic_daily WHERE chat_id = ?`, fromChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove merged daily topic points: %v", err)
+	}
+
+	moves := []struct {
+		table string
+		count *int64
+	}{
+		{"users", &cm.Users},
+		{"point_history", &cm.Points},
+		{"boosts", &cm.Boosts},
+		{"gifts", &cm.Gifts},
+		{"events", &cm.Events},
+		{"event_schedules", &cm.Schedules},
+		{"members", &cm.Members},
+		{"chat_settings", &cm.Settings},
+		{"warns", &cm.Warns},
+		{"filters", &cm.Filters},
+		{"captchas", &cm.Captchas},
+		{"reports", &cm.Reports},
+		{"report_subscribers", &cm.ReportSubscribers},
+		{"audit_log", &cm.AuditLog},
+		{"engagements", &cm.Engagements},
+	}
+
+	// Rows keyed by chat, like members or chat settings, may already exist in
+	// the new chat. Those are kept and the old ones are dropped instead of
+	// failing the whole migration on a conflict.
+	for _, mv := range moves {
+		query := fmt.Sprintf(`UPDATE OR IGNORE %s SET chat_id = ? WHERE chat_id = ?`, mv.table)
+		*mv.count, err = execCount(ctx, tx, query, toChatID, fromChatID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate %s: %v", mv.table, err)
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE chat_id = ?`, mv.table), fromChatID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove leftover %s: %v", mv.table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit chat migration: %v", err)
+	}
+
+	return cm, nil
+}
+
+// execCount executes query inside tx and returns the number of affected rows.
+func execCount(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
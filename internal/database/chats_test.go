@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMigrateKeepsExistingRows(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+	db := m.Points.DB
+
+	// Both chats know member 5, the old chat also knows member 6
+	seed := []string{
+		`INSERT INTO members(chat_id, user_id, messages) VALUES(1, 5, 3), (1, 6, 4), (2, 5, 9)`,
+		`INSERT INTO chat_settings(chat_id, probation_hours) VALUES(1, 12), (2, 48)`,
+		`INSERT INTO report_subscribers(chat_id, admin_id) VALUES(1, 7), (2, 7)`,
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm, err := m.Chats.Migrate(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if cm.Members != 1 {
+		t.Errorf("moved %d members, want 1", cm.Members)
+	}
+
+	var messages, hours, left int
+	if err := db.QueryRow(`SELECT messages FROM members WHERE chat_id = 2 AND user_id = 5`).Scan(&messages); err != nil {
+		t.Fatal(err)
+	}
+	if messages != 9 {
+		t.Errorf("member of the new chat has %d messages, want 9", messages)
+	}
+	if err := db.QueryRow(`SELECT probation_hours FROM chat_settings WHERE chat_id = 2`).Scan(&hours); err != nil {
+		t.Fatal(err)
+	}
+	if hours != 48 {
+		t.Errorf("new chat probation is %d hours, want 48", hours)
+	}
+
+	query := `SELECT (SELECT COUNT(*) FROM members WHERE chat_id = 1) +
+			 (SELECT COUNT(*) FROM chat_settings WHERE chat_id = 1) +
+			 (SELECT COUNT(*) FROM report_subscribers WHERE chat_id = 1)`
+	if err := db.QueryRow(query).Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Errorf("%d rows are left in the old chat, want 0", left)
+	}
+}
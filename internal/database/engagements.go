@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// EngagementModel handles operations related to the engagements table.
+type EngagementModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Inc counts one rewarded reply from replierID to authorID on the UTC day of
+// now and reports whether it is within limit. Replies over the limit are not
+// counted. A limit of 0 means unlimited.
+func (e EngagementModel) Inc(ctx context.Context, chatID, replierID, authorID int64, limit int, now time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	if limit <= 0 {
+		return true, nil
+	}
+
+	query := `INSERT INTO engagements(chat_id, day, replier_id, author_id, count) VALUES(?, ?, ?, ?, 1)
+		  ON CONFLICT(chat_id, day, replier_id, author_id) DO UPDATE SET count = count + 1 WHERE count < ?`
+
+	res, err := e.DB.ExecContext(ctx, query, chatID, now.UTC().Format(time.DateOnly), replierID, authorID, limit)
+	if err != nil {
+		return false, fmt.Errorf("failed to count engagement: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Prune deletes the counts of days before the UTC day of now.
+func (e EngagementModel) Prune(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	res, err := e.DB.ExecContext(ctx, `DELETE FROM engagements WHERE day < ?`, now.UTC().Format(time.DateOnly))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune engagements: %v", err)
+	}
+	return res.RowsAffected()
+}
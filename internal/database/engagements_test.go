@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEngagementInc(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 3; i++ {
+		allowed, err := m.Engagements.Inc(ctx, 1, 10, 20, 2, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i <= 2; allowed != want {
+			t.Errorf("reply %d: allowed = %v, want %v", i, allowed, want)
+		}
+	}
+
+	// Other pairs and the next day have their own count
+	if allowed, _ := m.Engagements.Inc(ctx, 1, 20, 10, 2, now); !allowed {
+		t.Error("reverse pair was capped")
+	}
+	if allowed, _ := m.Engagements.Inc(ctx, 1, 10, 20, 2, now.Add(24*time.Hour)); !allowed {
+		t.Error("next day was capped")
+	}
+
+	n, err := m.Engagements.Prune(ctx, now.Add(24*time.Hour))
+	if err != nil || n != 2 {
+		t.Errorf("Prune = %d, %v, want 2 pruned", n, err)
+	}
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// EventModel handles operations related to the events and event_schedules tables.
+type EventModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Event is a one-off chat-wide point multiplier.
+type Event struct {
+	ID         int64
+	ChatID     int64
+	Multiplier float64
+	StartsAt   time.Time
+	EndsAt     time.Time
+	CreatedBy  int64 // Telegram user ID of the admin who started the event
+}
+
+// Schedule is a chat-wide point multiplier that recurs every week.
+type Schedule struct {
+	ID         int64
+	ChatID     int64
+	Weekday    time.Weekday
+	StartTime  string // HH:MM
+	EndTime    string // HH:MM, before StartTime when the event runs past midnight
+	Multiplier float64
+	CreatedBy  int64
+	Active     bool // Whether the start was announced and the end was not yet
+}
+
+// Insert stores a new event and sets its ID.
+func (e EventModel) Insert(ctx context.Context, event *Event) error {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO events(chat_id, multiplier, starts_at, ends_at, created_by) VALUES(?, ?, ?, ?, ?)`
+
+	res, err := e.DB.ExecContext(ctx, query,
+		event.ChatID,
+		event.Multiplier,
+		event.StartsAt.UTC().Format(time.DateTime),
+		event.EndsAt.UTC().Format(time.DateTime),
+		event.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert event: %v", err)
+	}
+
+	event.ID, err = res.LastInsertId()
+	return err
+}
+
+// Active returns the events of a chat that are running at now.
+func (e EventModel) Active(ctx context.Context, chatID int64, now time.Time) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, multiplier, starts_at, ends_at, created_by FROM events
+		  WHERE chat_id = ? AND starts_at <= ? AND ends_at > ?
+		  ORDER BY ends_at`
+
+	at := now.UTC().Format(time.DateTime)
+	return e.events(ctx, query, chatID, at, at)
+}
+
+// Finished returns the events of every chat that ended before now and whose
+// end was not announced yet.
+func (e EventModel) Finished(ctx context.Context, now time.Time) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, multiplier, starts_at, ends_at, created_by FROM events
+		  WHERE ends_at <= ? AND end_announced = 0`
+
+	return e.events(ctx, query, now.UTC().Format(time.DateTime))
+}
+
+// MarkAnnounced records that the end of an event was posted in its chat.
+func (e EventModel) MarkAnnounced(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	_, err := e.DB.ExecContext(ctx, `UPDATE events SET end_announced = 1 WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to update event: %v", err)
+	}
+	return nil
+}
+
+// Stop ends every running event of a chat at now and returns how many were stopped.
+// Stopped events are announced by the caller, so they are marked as announced.
+func (e EventModel) Stop(ctx context.Context, chatID int64, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `UPDATE events SET ends_at = ?, end_announced = 1
+		  WHERE chat_id = ? AND starts_at <= ? AND ends_at > ?`
+
+	at := now.UTC().Format(time.DateTime)
+	res, err := e.DB.ExecContext(ctx, query, at, chatID, at, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stop events: %v", err)
+	}
+	return res.RowsAffected()
+}
+
+// events runs a query selecting events and scans the rows.
+func (e EventModel) events(ctx context.Context, query string, args ...any) ([]Event, error) {
+	rows, err := e.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %v", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		err := rows.Scan(
+			&event.ID,
+			&event.ChatID,
+			&event.Multiplier,
+			&event.StartsAt,
+			&event.EndsAt,
+			&event.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// InsertSchedule stores a new recurring event and sets its ID.
+func (e EventModel) InsertSchedule(ctx context.Context, schedule *Schedule) error {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO event_schedules(chat_id, weekday, start_time, end_time, multiplier, created_by) VALUES(?, ?, ?, ?, ?, ?)`
+
+	res, err := e.DB.ExecContext(ctx, query,
+		schedule.ChatID,
+		int(schedule.Weekday),
+		schedule.StartTime,
+		schedule.EndTime,
+		schedule.Multiplier,
+		schedule.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert event schedule: %v", err)
+	}
+
+	schedule.ID, err = res.LastInsertId()
+	return err
+}
+
+// Schedules returns the recurring events of a chat.
+func (e EventModel) Schedules(ctx context.Context, chatID int64) ([]Schedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, weekday, start_time, end_time, multiplier, created_by, active FROM event_schedules
+		  WHERE chat_id = ? ORDER BY weekday, start_time`
+
+	return e.schedules(ctx, query, chatID)
+}
+
+// AllSchedules returns the recurring events of every chat.
+func (e EventModel) AllSchedules(ctx context.Context) ([]Schedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, weekday, start_time, end_time, multiplier, created_by, active FROM event_schedules`
+
+	return e.schedules(ctx, query)
+}
+
+// DeleteSchedule removes a recurring event of a chat and reports whether it existed.
+func (e EventModel) DeleteSchedule(ctx context.Context, chatID, id int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	res, err := e.DB.ExecContext(ctx, `DELETE FROM event_schedules WHERE chat_id = ? AND id = ?`, chatID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete event schedule: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// SetScheduleActive records whether the start of a recurring event was announced.
+func (e EventModel) SetScheduleActive(ctx context.Context, id int64, active bool) error {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	_, err := e.DB.ExecContext(ctx, `UPDATE event_schedules SET active = ? WHERE id = ?`, active, id)
+	if err != nil {
+		return fmt.Errorf("failed to update event schedule: %v", err)
+	}
+	return nil
+}
+
+// schedules runs a query selecting recurring events and scans the rows.
+func (e EventModel) schedules(ctx context.Context, query string, args ...any) ([]Schedule, error) {
+	rows, err := e.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event schedules: %v", err)
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var schedule Schedule
+		var weekday int
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.ChatID,
+			&weekday,
+			&schedule.StartTime,
+			&schedule.EndTime,
+			&schedule.Multiplier,
+			&schedule.CreatedBy,
+			&schedule.Active,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		schedule.Weekday = time.Weekday(weekday)
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
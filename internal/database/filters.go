@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// FilterModel handles operations related to the filters table.
+type FilterModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Filter is a blocklist rule of a chat and what happens to messages that match it.
+type Filter struct {
+	ID        int64
+	ChatID    int64
+	Kind      string  // "word", "regex", "invite" or "domain"
+	Pattern   string  // The word, expression or domain, empty for invite filters
+	Action    string  // "delete", "warn", "mute" or "deduct"
+	Duration  string  // How long a mute lasts, e.g. "1h", empty for forever
+	Amount    float64 // Points a deduct action takes
+	CreatedBy int64   // The admin who added the filter
+	CreatedAt time.Time
+}
+
+// Insert stores a filter and sets its ID.
+func (f FilterModel) Insert(ctx context.Context, filter *Filter) error {
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO filters(chat_id, kind, pattern, action, duration, amount, created_by) VALUES(?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := f.DB.ExecContext(ctx, query,
+		filter.ChatID, filter.Kind, filter.Pattern, filter.Action, filter.Duration, filter.Amount, filter.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to insert filter: %v", err)
+	}
+
+	filter.ID, err = res.LastInsertId()
+	return err
+}
+
+// List returns the filters of a chat, oldest first.
+func (f FilterModel) List(ctx context.Context, chatID int64) ([]Filter, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, kind, pattern, action, duration, amount, created_by, created_at
+		  FROM filters WHERE chat_id = ? ORDER BY id`
+
+	rows, err := f.DB.QueryContext(ctx, query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query filters: %v", err)
+	}
+	defer rows.Close()
+
+	var filters []Filter
+	for rows.Next() {
+		var filter Filter
+		err := rows.Scan(
+			&filter.ID,
+			&filter.ChatID,
+			&filter.Kind,
+			&filter.Pattern,
+			&filter.Action,
+			&filter.Duration,
+			&filter.Amount,
+			&filter.CreatedBy,
+			&filter.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, rows.Err()
+}
+
+// Delete removes a filter of a chat and reports whether it existed.
+func (f FilterModel) Delete(ctx context.Context, chatID, id int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	res, err := f.DB.ExecContext(ctx, `DELETE FROM filters WHERE id = ? AND chat_id = ?`, id, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete filter: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -9,7 +9,8 @@ import (
 
 // GiftModel handles operations related to the Gift table.
 type GiftModel struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration
 }
 
 // Gift represents a gift transaction where a user sends points to another user.
@@ -23,8 +24,8 @@ type Gift struct {
 }
 
 // Insert saves a gift transaction in the database.
-func (gm GiftModel) Insert(gift *Gift) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (gm GiftModel) Insert(ctx context.Context, gift *Gift) error {
+	ctx, cancel := context.WithTimeout(ctx, gm.Timeout)
 	defer cancel()
 
 	query := `
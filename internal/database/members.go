@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MemberModel handles operations related to the members table.
+type MemberModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Member holds what the bot knows about a member's time in a chat.
+type Member struct {
+	ChatID   int64
+	UserID   int64
+	JoinedAt time.Time // Zero when the member joined before the bot was watching
+	Messages int       // Messages sent since joining
+	Trusted  bool      // Whether an admin let the member skip the probation
+}
+
+// Get returns a member, or nil if the bot never saw them join and they were never trusted.
+func (m MemberModel) Get(ctx context.Context, chatID, userID int64) (*Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, user_id, joined_at, messages, trusted FROM members WHERE chat_id = ? AND user_id = ?`
+
+	var member Member
+	var joinedAt sql.NullTime
+	err := m.DB.QueryRowContext(ctx, query, chatID, userID).Scan(
+		&member.ChatID,
+		&member.UserID,
+		&joinedAt,
+		&member.Messages,
+		&member.Trusted,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	member.JoinedAt = joinedAt.Time
+
+	return &member, nil
+}
+
+// Join records that a user joined a chat at joinedAt. Members who rejoin start
+// over with no messages, but stay trusted.
+func (m MemberModel) Join(ctx context.Context, chatID, userID int64, joinedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO members(chat_id, user_id, joined_at) VALUES(?, ?, ?)
+		  ON CONFLICT(chat_id, user_id) DO UPDATE SET joined_at = excluded.joined_at, messages = 0`
+
+	_, err := m.DB.ExecContext(ctx, query, chatID, userID, joinedAt.UTC().Format(time.DateTime))
+	if err != nil {
+		return fmt.Errorf("failed to record member join: %v", err)
+	}
+	return nil
+}
+
+// CountMessage adds a message to the member's count. Members the bot never saw
+// join are not tracked.
+func (m MemberModel) CountMessage(ctx context.Context, chatID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `UPDATE members SET messages = messages + 1 WHERE chat_id = ? AND user_id = ?`
+
+	_, err := m.DB.ExecContext(ctx, query, chatID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to count member message: %v", err)
+	}
+	return nil
+}
+
+// ClaimCaptchaBonus records that a member got the captcha bonus and reports
+// whether they got it for the first time, so leaving and rejoining can't earn it again.
+func (m MemberModel) ClaimCaptchaBonus(ctx context.Context, chatID, userID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO members(chat_id, user_id, captcha_bonus) VALUES(?, ?, 1)
+		  ON CONFLICT(chat_id, user_id) DO UPDATE SET captcha_bonus = 1 WHERE captcha_bonus = 0`
+
+	res, err := m.DB.ExecContext(ctx, query, chatID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim captcha bonus: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Trust marks a member as trusted, so they skip the probation.
+func (m MemberModel) Trust(ctx context.Context, chatID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO members(chat_id, user_id, trusted) VALUES(?, ?, 1)
+		  ON CONFLICT(chat_id, user_id) DO UPDATE SET trusted = 1`
+
+	_, err := m.DB.ExecContext(ctx, query, chatID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to trust member: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClaimCaptchaBonusOnce(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+
+	if err := m.Members.Join(ctx, 1, 2, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []bool{true, false} {
+		first, err := m.Members.ClaimCaptchaBonus(ctx, 1, 2)
+		if err != nil || first != want {
+			t.Errorf("claim %d = %v, %v, want %v", i+1, first, err, want)
+		}
+	}
+
+	// Rejoining does not make the bonus claimable again
+	if err := m.Members.Join(ctx, 1, 2, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if first, err := m.Members.ClaimCaptchaBonus(ctx, 1, 2); err != nil || first {
+		t.Errorf("claim after rejoining = %v, %v, want false", first, err)
+	}
+
+	// Members the bot never saw join can claim it too
+	if first, err := m.Members.ClaimCaptchaBonus(ctx, 1, 3); err != nil || !first {
+		t.Errorf("claim of unknown member = %v, %v, want true", first, err)
+	}
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MessageModel handles operations related to the messages table.
+type MessageModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Message records who sent a recent message in a chat.
+type Message struct {
+	ChatID    int64
+	MessageID int
+	UserID    int64    // Telegram user ID of the author
+	Points    float64  // Points the message earned its author
+	Scored    bool     // Whether the message went through scoring at all
+	Scale     *float64 // Multiplier the points were scored with, nil if it was not recorded
+	CreatedAt time.Time
+}
+
+// Insert records the author of a message.
+func (m MessageModel) Insert(ctx context.Context, msg *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `INSERT OR IGNORE INTO messages(chat_id, message_id, user_id) VALUES(?, ?, ?)`
+
+	_, err := m.DB.ExecContext(ctx, query, msg.ChatID, msg.MessageID, msg.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert message: %v", err)
+	}
+	return nil
+}
+
+// Get returns a recorded message, or nil if it is unknown or already pruned.
+func (m MessageModel) Get(ctx context.Context, chatID int64, messageID int) (*Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, message_id, user_id, points, scale, created_at FROM messages WHERE chat_id = ? AND message_id = ?`
+
+	var msg Message
+	var points sql.NullFloat64
+	err := m.DB.QueryRowContext(ctx, query, chatID, messageID).Scan(
+		&msg.ChatID,
+		&msg.MessageID,
+		&msg.UserID,
+		&points,
+		&msg.Scale,
+		&msg.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	msg.Points, msg.Scored = points.Float64, points.Valid
+
+	return &msg, nil
+}
+
+// Score records the points a message earned its author and the multiplier
+// they were scored with.
+func (m MessageModel) Score(ctx context.Context, chatID int64, messageID int, points, scale float64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `UPDATE messages SET points = ?, scale = ? WHERE chat_id = ? AND message_id = ?`
+
+	_, err := m.DB.ExecContext(ctx, query, points, scale, chatID, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to score message: %v", err)
+	}
+	return nil
+}
+
+// SetPoints updates the points a message earned its author.
+func (m MessageModel) SetPoints(ctx context.Context, chatID int64, messageID int, points float64) error {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `UPDATE messages SET points = ? WHERE chat_id = ? AND message_id = ?`
+
+	_, err := m.DB.ExecContext(ctx, query, points, chatID, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to update message points: %v", err)
+	}
+	return nil
+}
+
+// Range returns the recorded messages of a chat with IDs between fromID and toID (inclusive).
+func (m MessageModel) Range(ctx context.Context, chatID int64, fromID, toID int) ([]Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, message_id, user_id, points, created_at
+		  FROM messages
+		  WHERE chat_id = ? AND message_id BETWEEN ? AND ?
+		  ORDER BY message_id`
+
+	rows, err := m.DB.QueryContext(ctx, query, chatID, fromID, toID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %v", err)
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var points sql.NullFloat64
+		err := rows.Scan(&msg.ChatID, &msg.MessageID, &msg.UserID, &points, &msg.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		msg.Points, msg.Scored = points.Float64, points.Valid
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// Prune deletes messages, and the reactions to them, older than the given number of hours.
+func (m MessageModel) Prune(ctx context.Context, hours int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	age := fmt.Sprintf("-%d hours", hours)
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM reactions WHERE created_at < DATETIME('now', ?)`, age)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune reactions: %v", err)
+	}
+
+	res, err := m.DB.ExecContext(ctx, `DELETE FROM messages WHERE created_at < DATETIME('now', ?)`, age)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune messages: %v", err)
+	}
+
+	return res.RowsAffected()
+}
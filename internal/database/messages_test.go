@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMessageScore(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+
+	if err := m.Messages.Insert(ctx, &Message{ChatID: 1, MessageID: 7, UserID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := m.Messages.Get(ctx, 1, 7)
+	if err != nil || msg == nil {
+		t.Fatalf("Get = %v, %v", msg, err)
+	}
+	if msg.Scored || msg.Scale != nil {
+		t.Errorf("new message is scored: %+v", msg)
+	}
+
+	if err := m.Messages.Score(ctx, 1, 7, 3, 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Messages.SetPoints(ctx, 1, 7, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err = m.Messages.Get(ctx, 1, 7)
+	if err != nil || msg == nil {
+		t.Fatalf("Get = %v, %v", msg, err)
+	}
+	if !msg.Scored || msg.Points != 4 || msg.Scale == nil || *msg.Scale != 1.5 {
+		t.Errorf("scored message = %+v, want 4 points at scale 1.5", msg)
+	}
+}
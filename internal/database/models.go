@@ -3,28 +3,55 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"os"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/joybiswas007/modbot-tg/migrations"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/viper"
 )
 
+// defaultTimeout bounds a single query when bot.db.timeout is not configured.
+const defaultTimeout = 5 * time.Second
+
 type Models struct {
-	Users  UserModel
-	Points PointModel
-	Gifts  GiftModel
-	Shop   ItemModel
+	Users       UserModel
+	Points      PointModel
+	Gifts       GiftModel
+	Shop        ItemModel
+	Chats       ChatModel
+	Messages    MessageModel
+	Reactions   ReactionModel
+	Topics      TopicModel
+	Events      EventModel
+	Members     MemberModel
+	Settings    SettingModel
+	Warns       WarnModel
+	Filters     FilterModel
+	Captchas    CaptchaModel
+	Reports     ReportModel
+	Audit       AuditModel
+	Engagements EngagementModel
+}
+
+// dsn returns the path of the sqlite database. It is read from bot.db.path,
+// falling back to bot.db for configs that still use the plain string form.
+func dsn() string {
+	if path := viper.GetString("bot.db.path"); path != "" {
+		return path
+	}
+	return viper.GetString("bot.db")
 }
 
 func New() *sql.DB {
-	connStr := viper.GetString("bot.db")
+	connStr := dsn()
 	if connStr == "" {
-		log.Fatal("bot.db env variable can't be empty!")
+		log.Fatal("bot.db.path env variable can't be empty!")
 	}
 
 	db, err := sql.Open("sqlite3", connStr)
@@ -43,49 +70,70 @@ func New() *sql.DB {
 	return db
 }
 
+// NewModels wires every model to db. Each query is bounded by bot.db.timeout
+// on top of the context passed in by the caller.
 func NewModels(db *sql.DB) Models {
+	timeout := viper.GetDuration("bot.db.timeout")
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
 	return Models{
-		Users:  UserModel{DB: db},
-		Points: PointModel{DB: db},
-		Gifts:  GiftModel{DB: db},
-		Shop:   ItemModel{DB: db},
+		Users:       UserModel{DB: db, Timeout: timeout},
+		Points:      PointModel{DB: db, Timeout: timeout},
+		Gifts:       GiftModel{DB: db, Timeout: timeout},
+		Shop:        ItemModel{DB: db, Timeout: timeout},
+		Chats:       ChatModel{DB: db, Timeout: timeout},
+		Messages:    MessageModel{DB: db, Timeout: timeout},
+		Reactions:   ReactionModel{DB: db, Timeout: timeout},
+		Topics:      TopicModel{DB: db, Timeout: timeout},
+		Events:      EventModel{DB: db, Timeout: timeout},
+		Members:     MemberModel{DB: db, Timeout: timeout},
+		Settings:    SettingModel{DB: db, Timeout: timeout},
+		Warns:       WarnModel{DB: db, Timeout: timeout},
+		Filters:     FilterModel{DB: db, Timeout: timeout},
+		Captchas:    CaptchaModel{DB: db, Timeout: timeout},
+		Reports:     ReportModel{DB: db, Timeout: timeout},
+		Audit:       AuditModel{DB: db, Timeout: timeout},
+		Engagements: EngagementModel{DB: db, Timeout: timeout},
 	}
 }
 
-// Migrate applies database migrations for an sqlite3 database.
-// It reads migration files from the designated migration folder and
-// ensures the database schema is updated accordingly.
-func Migrate(db *sql.DB) error {
+// NewMigrate returns a migrate instance for an sqlite3 database that reads
+// its migration files from the set embedded in the binary.
+func NewMigrate(db *sql.DB) (*migrate.Migrate, error) {
 	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	cwd, err := os.Getwd()
+	source, err := iofs.New(migrations.FS, ".")
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read embedded migrations: %v", err)
 	}
 
-	migrationFilesPath := fmt.Sprintf("file://%s/migrations", cwd)
+	m, err := migrate.NewWithInstance("iofs", source, dsn(), driver)
+	if err != nil {
+		return nil, fmt.Errorf("migration initialization failed: %v", err)
+	}
 
-	m, err := migrate.NewWithDatabaseInstance(migrationFilesPath, viper.GetString("bot.db"), driver)
+	return m, nil
+}
+
+// Migrate applies all pending migrations for an sqlite3 database.
+// A dirty database is never reset automatically; the operator has to
+// inspect it and run "modbot migrate force N" before the bot will start.
+func Migrate(db *sql.DB) error {
+	m, err := NewMigrate(db)
 	if err != nil {
-		return fmt.Errorf("migration initialization failed: %v", err)
+		return err
 	}
 
 	err = m.Up()
 	if err != nil && err != migrate.ErrNoChange {
-		version, dirty, _ := m.Version()
-		if dirty {
-			log.Printf("Database is in a dirty state at version %d. Forcing reset...", version)
-			_ = m.Force(int(version)) // Reset to last successful version
-
-			//retry migration
-			err = m.Up()
-			if err != nil && err != migrate.ErrNoChange {
-				return fmt.Errorf("migration failed after fixing dirty state: %v", err)
-			}
-			return nil
+		var dirty migrate.ErrDirty
+		if errors.As(err, &dirty) {
+			return fmt.Errorf("database is in a dirty state at version %d, fix it and run \"modbot migrate force N\"", dirty.Version)
 		}
 		return fmt.Errorf("migration failed: %v", err)
 	}
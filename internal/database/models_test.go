@@ -0,0 +1,32 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// newTestModels returns models backed by a fresh, fully migrated database
+// that is removed when the test ends.
+func newTestModels(tb testing.TB) Models {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "modbot.db")
+	viper.Set("bot.db.path", path)
+	viper.Set("bot.db.timeout", time.Minute)
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	if err := Migrate(db); err != nil {
+		tb.Fatal(err)
+	}
+
+	return NewModels(db)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
 type PointModel struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration // Maximum duration of a single query
 }
 
 // Point represents a user's earned points in a chat
@@ -19,64 +21,82 @@ type Point struct {
 	Amount    float64   // Number of points awarded for a specific action.
 	Source    string    // Source of points "chatting" || "gift" || "double_coins"
 	Change    string    // Point was addec or deducted "gain" || "loss"
+	ThreadID  int       // Forum topic the points were earned in (0 outside of topics).
+	Reason    string    // Why the points were taken, empty when none was given.
 	TimeStamp time.Time // Timestamp when the points were recorded.
 }
 
-// Insert adds a new point record for a user in a chat
-func (p PointModel) Insert(point *Point) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// Insert adds a new point record for a user in a chat and adds its amount
+// to the user's daily totals, of the chat and of the topic, in the same transaction.
+func (p PointModel) Insert(ctx context.Context, point *Point) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
 	defer cancel()
 
-	query := `INSERT INTO point_history(chat_id, user_id, amount, change, source) VALUES(?, ?, ?, ?, ?)`
-
-	stmt, err := p.DB.PrepareContext(ctx, query)
+	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to prepare points statement: %v", err)
+		return fmt.Errorf("failed to begin points transaction: %v", err)
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
+
+	query := `INSERT INTO point_history(chat_id, user_id, amount, change, source, thread_id, reason) VALUES(?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = stmt.ExecContext(ctx, point.ChatID, point.UserID, point.Amount, point.Change, point.Source)
+	_, err = tx.ExecContext(ctx, query, point.ChatID, point.UserID, point.Amount, point.Change, point.Source, point.ThreadID, point.Reason)
 	if err != nil {
 		return fmt.Errorf("failed to execute points statement: %v", err)
 	}
 
+	gained, lost := point.Amount, 0.0
+	if point.Change == "loss" {
+		gained, lost = 0, point.Amount
+	}
+
+	rollup := `INSERT INTO point_daily(chat_id, day, user_id, gained, lost) VALUES(?, DATE('now'), ?, ?, ?)
+		   ON CONFLICT(chat_id, day, user_id) DO UPDATE SET gained = gained + excluded.gained, lost = lost + excluded.lost`
+
+	_, err = tx.ExecContext(ctx, rollup, point.ChatID, point.UserID, gained, lost)
+	if err != nil {
+		return fmt.Errorf("failed to update daily points: %v", err)
+	}
+
+	if point.ThreadID != 0 {
+		topicRollup := `INSERT INTO point_topic_daily(chat_id, thread_id, day, user_id, gained, lost) VALUES(?, ?, DATE('now'), ?, ?, ?)
+				ON CONFLICT(chat_id, thread_id, day, user_id) DO UPDATE SET gained = gained + excluded.gained, lost = lost + excluded.lost`
+
+		_, err = tx.ExecContext(ctx, topicRollup, point.ChatID, point.ThreadID, point.UserID, gained, lost)
+		if err != nil {
+			return fmt.Errorf("failed to update daily topic points: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit points transaction: %v", err)
+	}
+
 	return nil
 }
 
-// GetRanking retrieves the top users based on points for a given time period
+// GetRanking retrieves the top users based on their net points, gains minus
+// losses, in a given time period
 // (e.g.): "daily" || "weekly" || "monthly"
 // limit how many results we are fetching per request
-func (p PointModel) Ranking(chatID int64, limit int, period string) ([]Point, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// The ranking is served from the daily totals, so it keeps working after old
+// raw history has been pruned.
+func (p PointModel) Ranking(ctx context.Context, chatID int64, limit int, period string) ([]Point, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
 	defer cancel()
 
-	var query string
-	switch period {
-	case "daily":
-		query = `SELECT user_id, source, SUM(amount) AS total_points 
-		         FROM point_history 
-		         WHERE chat_id = ? AND DATE(timestamp) = DATE('now') 
-		         GROUP BY user_id 
-		         ORDER BY total_points DESC 
-		         LIMIT ?`
-	case "weekly":
-		query = `SELECT user_id, source, SUM(amount) AS total_points 
-		         FROM point_history 
-		         WHERE chat_id = ? 
-		         AND timestamp >= DATE('now', 'weekday 0', '-6 days') 
-		         GROUP BY user_id 
-		         ORDER BY total_points DESC 
-		         LIMIT ?`
-	case "monthly":
-		query = `SELECT user_id, source, SUM(amount) AS total_points 
-		         FROM point_history 
-		         WHERE chat_id = ? 
-		         AND strftime('%Y-%m', timestamp) = strftime('%Y-%m', 'now') 
-		         GROUP BY user_id 
-		         ORDER BY total_points DESC 
-		         LIMIT ?`
+	since, err := periodStart(period)
+	if err != nil {
+		return nil, err
 	}
 
+	query := fmt.Sprintf(`SELECT user_id, SUM(gained - lost) AS total_points
+		 FROM point_daily
+		 WHERE chat_id = ? AND day >= %s
+		 GROUP BY user_id
+		 ORDER BY total_points DESC
+		 LIMIT ?`, since)
+
 	rows, err := p.DB.QueryContext(ctx, query, chatID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute ranking query: %v", err)
@@ -86,7 +106,7 @@ func (p PointModel) Ranking(chatID int64, limit int, period string) ([]Point, er
 	var rankings []Point
 	for rows.Next() {
 		var p Point
-		err := rows.Scan(&p.UserID, &p.Source, &p.Amount)
+		err := rows.Scan(&p.UserID, &p.Amount)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %v", err)
 		}
@@ -105,13 +125,119 @@ func (p PointModel) Ranking(chatID int64, limit int, period string) ([]Point, er
 	return rankings, nil
 }
 
+// Earned returns the total points a user gained in a chat since the given time,
+// counting only records whose source is one of sources.
+func (p PointModel) Earned(ctx context.Context, chatID, userID int64, since time.Time, sources []string) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	if len(sources) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(sources)), ", ")
+	query := fmt.Sprintf(`SELECT COALESCE(SUM(amount), 0)
+		  FROM point_history
+		  WHERE chat_id = ? AND user_id = ? AND change = 'gain' AND timestamp >= ? AND source IN (%s)`, placeholders)
+
+	// timestamps are stored by SQLite as UTC "YYYY-MM-DD HH:MM:SS" text
+	args := []any{chatID, userID, since.UTC().Format(time.DateTime)}
+	for _, source := range sources {
+		args = append(args, source)
+	}
+
+	var earned float64
+	err := p.DB.QueryRowContext(ctx, query, args...).Scan(&earned)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum earned points: %v", err)
+	}
+
+	return earned, nil
+}
+
+// TopicRanking retrieves the top users based on their net points in a forum topic
+// during the period ("daily", "weekly" or "monthly"). Like Ranking it is served
+// from daily totals, so pruning raw history does not affect it.
+func (p PointModel) TopicRanking(ctx context.Context, chatID int64, threadID int, limit int, period string) ([]Point, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	since, err := periodStart(period)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT user_id, SUM(gained - lost) AS total_points
+		  FROM point_topic_daily
+		  WHERE chat_id = ? AND thread_id = ? AND day >= %s
+		  GROUP BY user_id
+		  ORDER BY total_points DESC
+		  LIMIT ?`, since)
+
+	rows, err := p.DB.QueryContext(ctx, query, chatID, threadID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute topic ranking query: %v", err)
+	}
+	defer rows.Close()
+
+	var rankings []Point
+	for rows.Next() {
+		p := Point{ChatID: chatID, ThreadID: threadID}
+		err := rows.Scan(&p.UserID, &p.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		rankings = append(rankings, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rankings) == 0 {
+		return nil, nil
+	}
+
+	return rankings, nil
+}
+
+// periodStart returns the SQL expression for the first day of a ranking period.
+func periodStart(period string) (string, error) {
+	switch period {
+	case "daily":
+		return `DATE('now')`, nil
+	case "weekly":
+		return `DATE('now', 'weekday 0', '-6 days')`, nil
+	case "monthly":
+		return `DATE('now', 'start of month')`, nil
+	default:
+		return "", fmt.Errorf("unknown ranking period %q", period)
+	}
+}
+
+// Prune deletes raw point history older than the given number of days.
+// Daily totals are kept, so rankings are not affected.
+func (p PointModel) Prune(ctx context.Context, days int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM point_history WHERE timestamp < DATETIME('now', ?)`
+
+	res, err := p.DB.ExecContext(ctx, query, fmt.Sprintf("-%d days", days))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune point history: %v", err)
+	}
+
+	return res.RowsAffected()
+}
+
 // History retrieves the point history for a specific user in a given chat.
 // It fetches the most recent records based on the provided limit.
-func (p PointModel) History(chatID, userID int64, limit int) ([]Point, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (p PointModel) History(ctx context.Context, chatID, userID int64, limit int) ([]Point, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
 	defer cancel()
 
-	query := `SELECT chat_id, user_id, amount, change, source, timestamp
+	query := `SELECT chat_id, user_id, amount, change, source, reason, timestamp
 		  FROM point_history
 		  WHERE chat_id = ? AND user_id = ?
 		  ORDER BY timestamp DESC
@@ -126,7 +252,7 @@ func (p PointModel) History(chatID, userID int64, limit int) ([]Point, error) {
 	var points []Point
 	for rows.Next() {
 		var p Point
-		err := rows.Scan(&p.ChatID, &p.UserID, &p.Amount, &p.Change, &p.Source, &p.TimeStamp)
+		err := rows.Scan(&p.ChatID, &p.UserID, &p.Amount, &p.Change, &p.Source, &p.Reason, &p.TimeStamp)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %v", err)
 		}
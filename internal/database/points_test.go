@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+// seedPoints fills a chat with rows point records spread over users and the
+// last 60 days, and rebuilds the daily totals the same way migration 7 does.
+func seedPoints(tb testing.TB, m Models, chatID int64, rows, users int) {
+	tb.Helper()
+
+	seed := `WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < ?)
+		 INSERT INTO point_history(chat_id, user_id, amount, change, source, timestamp)
+		 SELECT ?, n % ?, n % 5 + 1, 'gain', 'chatting', DATETIME('now', '-' || (n % 60) || ' days') FROM seq`
+	if _, err := m.Points.DB.Exec(seed, rows, chatID, users); err != nil {
+		tb.Fatal(err)
+	}
+
+	rollup := `INSERT INTO point_daily(chat_id, day, user_id, gained, lost)
+		   SELECT chat_id, DATE(timestamp), user_id, SUM(amount), 0
+		   FROM point_history WHERE chat_id = ?
+		   GROUP BY chat_id, DATE(timestamp), user_id`
+	if _, err := m.Points.DB.Exec(rollup, chatID); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// BenchmarkRanking measures the ranking of a chat with a million point records.
+// Run it with: go test ./internal/database -run '^$' -bench Ranking
+func BenchmarkRanking(b *testing.B) {
+	m := newTestModels(b)
+	seedPoints(b, m, 1, 1_000_000, 5000)
+	ctx := context.Background()
+
+	for _, period := range []string{"daily", "weekly", "monthly"} {
+		b.Run(period, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ranking, err := m.Points.Ranking(ctx, 1, 10, period)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(ranking) != 10 {
+					b.Fatalf("got %d ranked users, want 10", len(ranking))
+				}
+			}
+		})
+	}
+}
+
+func TestRankingCountsLosses(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+
+	points := []Point{
+		{ChatID: 1, UserID: 1, Amount: 10, Change: "gain", Source: "chatting", ThreadID: 7},
+		{ChatID: 1, UserID: 1, Amount: 8, Change: "loss", Source: "seize", ThreadID: 7},
+		{ChatID: 1, UserID: 2, Amount: 5, Change: "gain", Source: "chatting", ThreadID: 7},
+	}
+	for i := range points {
+		if err := m.Points.Insert(ctx, &points[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []Point{{UserID: 2, Amount: 5}, {UserID: 1, Amount: 2}}
+
+	ranking, err := m.Points.Ranking(ctx, 1, 10, "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics, err := m.Points.TopicRanking(ctx, 1, 7, 10, "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, got := range map[string][]Point{"Ranking": ranking, "TopicRanking": topics} {
+		if len(got) != len(want) {
+			t.Fatalf("%s returned %d users, want %d", name, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].UserID != want[i].UserID || got[i].Amount != want[i].Amount {
+				t.Errorf("%s[%d] = user %d with %v, want user %d with %v", name, i, got[i].UserID, got[i].Amount, want[i].UserID, want[i].Amount)
+			}
+		}
+	}
+}
+
+func TestTopicRankingSurvivesPruning(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+
+	point := &Point{ChatID: 1, UserID: 1, Amount: 3, Change: "gain", Source: "chatting", ThreadID: 7}
+	if err := m.Points.Insert(ctx, point); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Points.DB.Exec(`DELETE FROM point_history`); err != nil {
+		t.Fatal(err)
+	}
+
+	ranking, err := m.Points.TopicRanking(ctx, 1, 7, 10, "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranking) != 1 || ranking[0].Amount != 3 {
+		t.Errorf("TopicRanking() = %+v, want user 1 with 3 points", ranking)
+	}
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReactionModel handles operations related to the reactions table.
+type ReactionModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Reaction is a reaction that earned the author of a message points.
+type Reaction struct {
+	ChatID    int64
+	MessageID int
+	ReactorID int64   // User who reacted
+	Reaction  string  // Emoji, "custom:<id>" or "paid"
+	AuthorID  int64   // User who wrote the message and received the points
+	Points    float64 // Points awarded for this reaction
+	CreatedAt time.Time
+}
+
+// Insert records a rewarded reaction. It reports false without storing anything
+// if the reactor already got the message rewarded for this reaction, even if
+// they removed it since.
+func (r ReactionModel) Insert(ctx context.Context, reaction *Reaction) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `INSERT OR IGNORE INTO reactions(chat_id, message_id, reactor_id, reaction, author_id, points) VALUES(?, ?, ?, ?, ?, ?)`
+
+	res, err := r.DB.ExecContext(ctx, query, reaction.ChatID, reaction.MessageID, reaction.ReactorID, reaction.Reaction, reaction.AuthorID, reaction.Points)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert reaction: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Revoke marks a rewarded reaction as removed and returns it, or nil if it was
+// never rewarded or is already revoked. The row is kept so it still counts
+// toward the caps.
+func (r ReactionModel) Revoke(ctx context.Context, chatID int64, messageID int, reactorID int64, reaction string) (*Reaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `UPDATE reactions SET revoked = 1
+		  WHERE chat_id = ? AND message_id = ? AND reactor_id = ? AND reaction = ? AND revoked = 0
+		  RETURNING chat_id, message_id, reactor_id, reaction, author_id, points, created_at`
+
+	var re Reaction
+	err := r.DB.QueryRowContext(ctx, query, chatID, messageID, reactorID, reaction).Scan(
+		&re.ChatID,
+		&re.MessageID,
+		&re.ReactorID,
+		&re.Reaction,
+		&re.AuthorID,
+		&re.Points,
+		&re.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &re, nil
+}
+
+// MessageTotal returns the points a message has earned from reactions so far,
+// including revoked ones.
+func (r ReactionModel) MessageTotal(ctx context.Context, chatID int64, messageID int) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `SELECT COALESCE(SUM(points), 0) FROM reactions WHERE chat_id = ? AND message_id = ?`
+
+	var total float64
+	err := r.DB.QueryRowContext(ctx, query, chatID, messageID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum message reactions: %v", err)
+	}
+	return total, nil
+}
+
+// CountByReactor returns how many rewarded reactions a user has given in a chat
+// since the given time, including revoked ones.
+func (r ReactionModel) CountByReactor(ctx context.Context, chatID, reactorID int64, since time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM reactions WHERE chat_id = ? AND reactor_id = ? AND created_at >= ?`
+
+	var count int
+	err := r.DB.QueryRowContext(ctx, query, chatID, reactorID, since.UTC().Format(time.DateTime)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reactions: %v", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRevokedReactionsStayCounted(t *testing.T) {
+	m := newTestModels(t)
+	ctx := context.Background()
+
+	reaction := &Reaction{ChatID: 1, MessageID: 10, ReactorID: 2, Reaction: "🔥", AuthorID: 3, Points: 2}
+	if stored, err := m.Reactions.Insert(ctx, reaction); err != nil || !stored {
+		t.Fatalf("Insert() = %v, %v, want true", stored, err)
+	}
+
+	revoked, err := m.Reactions.Revoke(ctx, 1, 10, 2, "🔥")
+	if err != nil || revoked == nil || revoked.Points != 2 {
+		t.Fatalf("Revoke() = %+v, %v, want the reaction", revoked, err)
+	}
+	if again, err := m.Reactions.Revoke(ctx, 1, 10, 2, "🔥"); err != nil || again != nil {
+		t.Errorf("second Revoke() = %+v, %v, want nil", again, err)
+	}
+
+	// Adding the same reaction again must not be rewarded twice
+	if stored, err := m.Reactions.Insert(ctx, reaction); err != nil || stored {
+		t.Errorf("Insert() after Revoke() = %v, %v, want false", stored, err)
+	}
+
+	total, err := m.Reactions.MessageTotal(ctx, 1, 10)
+	if err != nil || total != 2 {
+		t.Errorf("MessageTotal() = %v, %v, want 2", total, err)
+	}
+	count, err := m.Reactions.CountByReactor(ctx, 1, 2, time.Now().Add(-time.Hour))
+	if err != nil || count != 1 {
+		t.Errorf("CountByReactor() = %v, %v, want 1", count, err)
+	}
+}
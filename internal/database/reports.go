@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReportModel handles operations related to the reports and report_subscribers tables.
+type ReportModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Report is a message a member reported to the admins.
+type Report struct {
+	ID         int64
+	ChatID     int64
+	MessageID  int    // The reported message
+	UserID     int64  // Author of the reported message
+	ReporterID int64  // The member who reported it
+	Reason     string // Optional, empty when none was given
+	Status     string // "open" until an admin handles it
+	HandledBy  int64  // The admin who handled it, 0 while open
+	CreatedAt  time.Time
+}
+
+// Insert stores a report and sets its ID. It reports false without storing
+// anything if the message was already reported.
+func (r ReportModel) Insert(ctx context.Context, report *Report) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `INSERT OR IGNORE INTO reports(chat_id, message_id, user_id, reporter_id, reason) VALUES(?, ?, ?, ?, ?)`
+
+	res, err := r.DB.ExecContext(ctx, query, report.ChatID, report.MessageID, report.UserID, report.ReporterID, report.Reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert report: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	report.ID, err = res.LastInsertId()
+	return err == nil, err
+}
+
+// Get returns a report of a chat, or nil if it does not exist.
+func (r ReportModel) Get(ctx context.Context, chatID, id int64) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, message_id, user_id, reporter_id, reason, status, handled_by, created_at
+		  FROM reports WHERE id = ? AND chat_id = ?`
+
+	var report Report
+	var handledBy sql.NullInt64
+	err := r.DB.QueryRowContext(ctx, query, id, chatID).Scan(
+		&report.ID,
+		&report.ChatID,
+		&report.MessageID,
+		&report.UserID,
+		&report.ReporterID,
+		&report.Reason,
+		&report.Status,
+		&handledBy,
+		&report.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	report.HandledBy = handledBy.Int64
+
+	return &report, nil
+}
+
+// Resolve closes an open report with status and reports whether it was still
+// open, so a report can only be handled once.
+func (r ReportModel) Resolve(ctx context.Context, chatID, id int64, status string, adminID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `UPDATE reports SET status = ?, handled_by = ?, handled_at = ?
+		  WHERE id = ? AND chat_id = ? AND status = 'open'`
+
+	res, err := r.DB.ExecContext(ctx, query, status, adminID, time.Now().UTC().Format(time.DateTime), id, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve report: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Delete removes a report of a chat.
+func (r ReportModel) Delete(ctx context.Context, chatID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM reports WHERE id = ? AND chat_id = ?`, id, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to delete report: %v", err)
+	}
+	return nil
+}
+
+// Subscribe makes an admin get the new reports of a chat in private messages, or stop getting them.
+func (r ReportModel) Subscribe(ctx context.Context, chatID, adminID int64, subscribe bool) error {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM report_subscribers WHERE chat_id = ? AND admin_id = ?`
+	if subscribe {
+		query = `INSERT OR IGNORE INTO report_subscribers(chat_id, admin_id) VALUES(?, ?)`
+	}
+
+	_, err := r.DB.ExecContext(ctx, query, chatID, adminID)
+	if err != nil {
+		return fmt.Errorf("failed to update report subscription: %v", err)
+	}
+	return nil
+}
+
+// Subscribers returns the admins who get the new reports of a chat in private messages.
+func (r ReportModel) Subscribers(ctx context.Context, chatID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT admin_id FROM report_subscribers WHERE chat_id = ?`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report subscribers: %v", err)
+	}
+	defer rows.Close()
+
+	var admins []int64
+	for rows.Next() {
+		var adminID int64
+		if err := rows.Scan(&adminID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		admins = append(admins, adminID)
+	}
+
+	return admins, rows.Err()
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SettingModel handles operations related to the chat_settings table.
+type SettingModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// ChatSettings holds the settings admins changed for a chat.
+// Settings that were never changed are nil and fall back to the config file.
+type ChatSettings struct {
+	ChatID    int64
+	Probation *Probation
+	Greetings Greetings
+	LogChatID int64 // Channel admin actions are posted to, 0 for none
+	UpdatedAt time.Time
+}
+
+// Greetings are the messages the bot sends when members join or leave a chat.
+// Every field is nil until an admin changes it.
+type Greetings struct {
+	Welcome     *string // Template for members who join
+	Goodbye     *string // Template for members who leave
+	Enabled     *bool   // Whether greetings are sent at all
+	DeleteAfter *int    // Seconds after which greetings are deleted, 0 keeps them
+	RulesLink   *string // Link to the chat rules, for the {rules} placeholder
+}
+
+// Probation is how long newcomers earn a reduced rate after joining.
+type Probation struct {
+	Hours    int     // Hours after joining, 0 to ignore
+	Messages int     // Messages after joining, 0 to ignore
+	Rate     float64 // Fraction of the usual points earned meanwhile
+}
+
+// Get returns the settings of a chat, or nil if none were changed.
+func (s SettingModel) Get(ctx context.Context, chatID int64) (*ChatSettings, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, probation_hours, probation_messages, probation_rate,
+			welcome_text, goodbye_text, greetings_enabled, greetings_delete_after, rules_link, log_chat_id, updated_at
+		  FROM chat_settings WHERE chat_id = ?`
+
+	var settings ChatSettings
+	var hours, messages, deleteAfter, logChatID sql.NullInt64
+	var rate sql.NullFloat64
+	var welcome, goodbye, rules sql.NullString
+	var enabled sql.NullBool
+	err := s.DB.QueryRowContext(ctx, query, chatID).Scan(
+		&settings.ChatID,
+		&hours,
+		&messages,
+		&rate,
+		&welcome,
+		&goodbye,
+		&enabled,
+		&deleteAfter,
+		&rules,
+		&logChatID,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if hours.Valid {
+		settings.Probation = &Probation{
+			Hours:    int(hours.Int64),
+			Messages: int(messages.Int64),
+			Rate:     rate.Float64,
+		}
+	}
+
+	if welcome.Valid {
+		settings.Greetings.Welcome = &welcome.String
+	}
+	if goodbye.Valid {
+		settings.Greetings.Goodbye = &goodbye.String
+	}
+	if enabled.Valid {
+		settings.Greetings.Enabled = &enabled.Bool
+	}
+	if deleteAfter.Valid {
+		seconds := int(deleteAfter.Int64)
+		settings.Greetings.DeleteAfter = &seconds
+	}
+	if rules.Valid {
+		settings.Greetings.RulesLink = &rules.String
+	}
+	settings.LogChatID = logChatID.Int64
+
+	return &settings, nil
+}
+
+// SetProbation sets the newcomer probation of a chat.
+func (s SettingModel) SetProbation(ctx context.Context, chatID int64, probation Probation) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO chat_settings(chat_id, probation_hours, probation_messages, probation_rate, updated_at) VALUES(?, ?, ?, ?, ?)
+		  ON CONFLICT(chat_id) DO UPDATE SET
+			probation_hours = excluded.probation_hours,
+			probation_messages = excluded.probation_messages,
+			probation_rate = excluded.probation_rate,
+			updated_at = excluded.updated_at`
+
+	_, err := s.DB.ExecContext(ctx, query, chatID, probation.Hours, probation.Messages, probation.Rate, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set probation: %v", err)
+	}
+	return nil
+}
+
+// SetWelcome sets the welcome template of a chat.
+func (s SettingModel) SetWelcome(ctx context.Context, chatID int64, text string) error {
+	return s.set(ctx, chatID, "welcome_text", text)
+}
+
+// SetGoodbye sets the goodbye template of a chat.
+func (s SettingModel) SetGoodbye(ctx context.Context, chatID int64, text string) error {
+	return s.set(ctx, chatID, "goodbye_text", text)
+}
+
+// SetGreetingsEnabled turns the greetings of a chat on or off.
+func (s SettingModel) SetGreetingsEnabled(ctx context.Context, chatID int64, enabled bool) error {
+	return s.set(ctx, chatID, "greetings_enabled", enabled)
+}
+
+// SetGreetingsDeleteAfter sets after how many seconds greetings are deleted, 0 keeps them.
+func (s SettingModel) SetGreetingsDeleteAfter(ctx context.Context, chatID int64, seconds int) error {
+	return s.set(ctx, chatID, "greetings_delete_after", seconds)
+}
+
+// SetRulesLink sets the link to the rules of a chat.
+func (s SettingModel) SetRulesLink(ctx context.Context, chatID int64, link string) error {
+	return s.set(ctx, chatID, "rules_link", link)
+}
+
+// SetLogChat sets the channel admin actions of a chat are posted to, 0 for none.
+func (s SettingModel) SetLogChat(ctx context.Context, chatID, logChatID int64) error {
+	return s.set(ctx, chatID, "log_chat_id", logChatID)
+}
+
+// set changes a single setting of a chat. column is never user input.
+func (s SettingModel) set(ctx context.Context, chatID int64, column string, value any) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`INSERT INTO chat_settings(chat_id, %[1]s, updated_at) VALUES(?, ?, ?)
+		  ON CONFLICT(chat_id) DO UPDATE SET %[1]s = excluded.%[1]s, updated_at = excluded.updated_at`, column)
+
+	_, err := s.DB.ExecContext(ctx, query, chatID, value, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set %s: %v", column, err)
+	}
+	return nil
+}
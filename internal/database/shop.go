@@ -8,7 +8,8 @@ import (
 )
 
 type ItemModel struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration
 }
 
 // Item represents an item available for purchase in the shop
@@ -23,8 +24,8 @@ type Item struct {
 }
 
 // Get returns the specific item id
-func (item ItemModel) Get(itemID int64) (*Item, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (item ItemModel) Get(ctx context.Context, itemID int64) (*Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, item.Timeout)
 	defer cancel()
 
 	query := `SELECT * FROM shop WHERE id = ?`
@@ -51,8 +52,8 @@ func (item ItemModel) Get(itemID int64) (*Item, error) {
 }
 
 // Items returns all the availabl item frm shop
-func (item ItemModel) Items() ([]Item, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (item ItemModel) Items(ctx context.Context) ([]Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, item.Timeout)
 	defer cancel()
 
 	rows, err := item.DB.QueryContext(ctx, `SELECT * FROM shop`)
@@ -88,8 +89,8 @@ func (item ItemModel) Items() ([]Item, error) {
 	return items, nil
 }
 
-func (item ItemModel) Buy(userID, chatID, itemID int64, boostType string, duration int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (item ItemModel) Buy(ctx context.Context, userID, chatID, itemID int64, boostType string, duration int) error {
+	ctx, cancel := context.WithTimeout(ctx, item.Timeout)
 	defer cancel()
 
 	var expiresAt *time.Time
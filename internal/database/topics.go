@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TopicModel handles operations related to the topics table.
+type TopicModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Topic holds the settings of a forum topic.
+type Topic struct {
+	ChatID     int64
+	ThreadID   int     // Forum topic ID, 0 for the General topic
+	Multiplier float64 // Multiplier for points earned by messages in the topic
+	UpdatedAt  time.Time
+}
+
+// Get returns the settings of a topic, or nil if none were set.
+func (t TopicModel) Get(ctx context.Context, chatID int64, threadID int) (*Topic, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	query := `SELECT chat_id, thread_id, multiplier, updated_at FROM topics WHERE chat_id = ? AND thread_id = ?`
+
+	var topic Topic
+	err := t.DB.QueryRowContext(ctx, query, chatID, threadID).Scan(
+		&topic.ChatID,
+		&topic.ThreadID,
+		&topic.Multiplier,
+		&topic.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &topic, nil
+}
+
+// SetMultiplier sets the point multiplier of a topic.
+func (t TopicModel) SetMultiplier(ctx context.Context, chatID int64, threadID int, multiplier float64) error {
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	query := `INSERT INTO topics(chat_id, thread_id, multiplier, updated_at) VALUES(?, ?, ?, ?)
+		  ON CONFLICT(chat_id, thread_id) DO UPDATE SET multiplier = excluded.multiplier, updated_at = excluded.updated_at`
+
+	_, err := t.DB.ExecContext(ctx, query, chatID, threadID, multiplier, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set topic multiplier: %v", err)
+	}
+	return nil
+}
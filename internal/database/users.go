@@ -9,7 +9,8 @@ import (
 )
 
 type UserModel struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration // Maximum duration of a single query
 }
 
 // User represents a participant in a Telegram chat with tracked points.
@@ -34,8 +35,8 @@ type Boost struct {
 }
 
 // Get retrieves a user’s data from the database for a specific chat.
-func (u UserModel) Get(chatID, userID int64) (*User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (u UserModel) Get(ctx context.Context, chatID, userID int64) (*User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
 	defer cancel()
 
 	query := `SELECT * FROM users WHERE chat_id = ? AND user_id = ?`
@@ -61,11 +62,14 @@ func (u UserModel) Get(chatID, userID int64) (*User, error) {
 }
 
 // Insert adds a new user entry into the database.
-func (u UserModel) Insert(chatID, userID int64, point float64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// If the user already exists in the chat (e.g. two first messages raced each
+// other) the point is added to the existing balance instead.
+func (u UserModel) Insert(ctx context.Context, chatID, userID int64, point float64) error {
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
 	defer cancel()
 
-	query := `INSERT INTO users(user_id, chat_id, points, created_at, updated_at) VALUES(?, ?, ?, ?, ?)`
+	query := `INSERT INTO users(user_id, chat_id, points, created_at, updated_at) VALUES(?, ?, ?, ?, ?)
+		  ON CONFLICT(chat_id, user_id) DO UPDATE SET points = points + excluded.points, updated_at = excluded.updated_at`
 
 	stmt, err := u.DB.PrepareContext(ctx, query)
 	if err != nil {
@@ -82,8 +86,8 @@ func (u UserModel) Insert(chatID, userID int64, point float64) error {
 }
 
 // Update modifies the points of an existing user in the database.
-func (u UserModel) Update(chatID, userID int64, point float64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (u UserModel) Update(ctx context.Context, chatID, userID int64, point float64) error {
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
 	defer cancel()
 
 	query := `UPDATE users SET points = ?, updated_at = ? WHERE chat_id = ? AND user_id = ?`
@@ -96,8 +100,8 @@ func (u UserModel) Update(chatID, userID int64, point float64) error {
 }
 
 // Leaderboard fetches the top N users with the highest points in a specific chat.
-func (u UserModel) Leaderboard(chatID int64, topN int) ([]User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (u UserModel) Leaderboard(ctx context.Context, chatID int64, topN int) ([]User, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
 	defer cancel()
 
 	query := `SELECT user_id, points, updated_at FROM users WHERE chat_id = ? ORDER BY points DESC LIMIT ?`
@@ -137,8 +141,8 @@ func (u UserModel) Leaderboard(chatID int64, topN int) ([]User, error) {
 }
 
 // GetBoostByItem retrieves a specific boost item for a user in a chat based on itemID.
-func (u UserModel) GetBoostByItem(userID, chatID, itemID int64) (*Boost, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (u UserModel) GetBoostByItem(ctx context.Context, userID, chatID, itemID int64) (*Boost, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
 	defer cancel()
 
 	query := `SELECT id, user_id, chat_id, item_id, boost_type, purchased_at, expires_at 
@@ -166,8 +170,8 @@ func (u UserModel) GetBoostByItem(userID, chatID, itemID int64) (*Boost, error)
 }
 
 // ActiveBoost returns the first active boost for the given user and chat.
-func (u UserModel) ActiveBoost(userID, chatID int64) (*Boost, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (u UserModel) ActiveBoost(ctx context.Context, userID, chatID int64) (*Boost, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.Timeout)
 	defer cancel()
 
 	query := `SELECT id, user_id, chat_id, item_id, boost_type, purchased_at, expires_at 
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WarnModel handles operations related to the warns table.
+type WarnModel struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+// Warn is a warning an admin gave a member.
+type Warn struct {
+	ID        int64
+	ChatID    int64
+	UserID    int64  // The warned user
+	AdminID   int64  // The admin who issued the warning
+	Reason    string // Optional, empty when none was given
+	CreatedAt time.Time
+}
+
+// Insert stores a warning and returns how many warnings the user now has in the chat.
+func (w WarnModel) Insert(ctx context.Context, warn *Warn) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	tx, err := w.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO warns(chat_id, user_id, admin_id, reason) VALUES(?, ?, ?, ?)`
+
+	res, err := tx.ExecContext(ctx, query, warn.ChatID, warn.UserID, warn.AdminID, warn.Reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert warn: %v", err)
+	}
+
+	warn.ID, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM warns WHERE chat_id = ? AND user_id = ?`, warn.ChatID, warn.UserID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count warns: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit warn: %v", err)
+	}
+
+	return count, nil
+}
+
+// List returns the warnings of a user in a chat, oldest first.
+func (w WarnModel) List(ctx context.Context, chatID, userID int64) ([]Warn, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	query := `SELECT id, chat_id, user_id, admin_id, reason, created_at FROM warns
+		  WHERE chat_id = ? AND user_id = ? ORDER BY id`
+
+	rows, err := w.DB.QueryContext(ctx, query, chatID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query warns: %v", err)
+	}
+	defer rows.Close()
+
+	var warns []Warn
+	for rows.Next() {
+		var warn Warn
+		err := rows.Scan(
+			&warn.ID,
+			&warn.ChatID,
+			&warn.UserID,
+			&warn.AdminID,
+			&warn.Reason,
+			&warn.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		warns = append(warns, warn)
+	}
+
+	return warns, rows.Err()
+}
+
+// RemoveLatest deletes the most recent warning of a user and reports whether there was one.
+func (w WarnModel) RemoveLatest(ctx context.Context, chatID, userID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	query := `DELETE FROM warns WHERE id = (SELECT MAX(id) FROM warns WHERE chat_id = ? AND user_id = ?)`
+
+	res, err := w.DB.ExecContext(ctx, query, chatID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove warn: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Reset deletes every warning of a user in a chat and returns how many were deleted.
+func (w WarnModel) Reset(ctx context.Context, chatID, userID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	res, err := w.DB.ExecContext(ctx, `DELETE FROM warns WHERE chat_id = ? AND user_id = ?`, chatID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset warns: %v", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_point_history_chat_user";
+DROP INDEX IF EXISTS "idx_point_history_chat_timestamp";
+DROP INDEX IF EXISTS "idx_users_chat_user";
@@ -0,0 +1,13 @@
+-- Merge duplicate (chat_id, user_id) rows into the oldest row of each group,
+-- summing the balances and keeping the earliest created_at.
+UPDATE users SET
+    points = (SELECT SUM(d.points) FROM users d WHERE d.chat_id = users.chat_id AND d.user_id = users.user_id),
+    created_at = (SELECT MIN(d.created_at) FROM users d WHERE d.chat_id = users.chat_id AND d.user_id = users.user_id),
+    updated_at = (SELECT MAX(d.updated_at) FROM users d WHERE d.chat_id = users.chat_id AND d.user_id = users.user_id)
+WHERE id IN (SELECT MIN(id) FROM users GROUP BY chat_id, user_id HAVING COUNT(*) > 1);
+
+DELETE FROM users WHERE id NOT IN (SELECT MIN(id) FROM users GROUP BY chat_id, user_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_chat_user" ON "users" ("chat_id", "user_id");
+CREATE INDEX IF NOT EXISTS "idx_point_history_chat_timestamp" ON "point_history" ("chat_id", "timestamp");
+CREATE INDEX IF NOT EXISTS "idx_point_history_chat_user" ON "point_history" ("chat_id", "user_id");
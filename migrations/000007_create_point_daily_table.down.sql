@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_point_history_timestamp";
+DROP TABLE IF EXISTS "point_daily";
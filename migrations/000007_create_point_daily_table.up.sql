@@ -0,0 +1,19 @@
+-- Per-user daily totals, maintained on every point_history insert so rankings
+-- never have to scan the raw history.
+CREATE TABLE IF NOT EXISTS "point_daily" (
+	"chat_id" INTEGER NOT NULL,
+	"day" DATE NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"gained" REAL NOT NULL DEFAULT 0,
+	"lost" REAL NOT NULL DEFAULT 0,
+	PRIMARY KEY("chat_id", "day", "user_id")
+);
+
+INSERT INTO point_daily (chat_id, day, user_id, gained, lost)
+SELECT chat_id, DATE(timestamp), user_id,
+       SUM(CASE WHEN change = 'gain' THEN amount ELSE 0 END),
+       SUM(CASE WHEN change = 'loss' THEN amount ELSE 0 END)
+FROM point_history
+GROUP BY chat_id, DATE(timestamp), user_id;
+
+CREATE INDEX IF NOT EXISTS "idx_point_history_timestamp" ON "point_history" ("timestamp");
@@ -0,0 +1,25 @@
+-- Authors of recent messages, so reactions can be credited to them
+CREATE TABLE IF NOT EXISTS "messages" (
+	"chat_id" INTEGER NOT NULL,
+	"message_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("chat_id", "message_id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_messages_created_at" ON "messages" ("created_at");
+
+-- Reactions that earned the message author points, kept so they can be revoked
+CREATE TABLE IF NOT EXISTS "reactions" (
+	"chat_id" INTEGER NOT NULL,
+	"message_id" INTEGER NOT NULL,
+	"reactor_id" INTEGER NOT NULL,
+	"reaction" TEXT NOT NULL, -- emoji, "custom:<id>" or "paid"
+	"author_id" INTEGER NOT NULL,
+	"points" REAL NOT NULL,
+	"created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("chat_id", "message_id", "reactor_id", "reaction")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_reactions_reactor" ON "reactions" ("chat_id", "reactor_id", "created_at");
+CREATE INDEX IF NOT EXISTS "idx_reactions_created_at" ON "reactions" ("created_at");
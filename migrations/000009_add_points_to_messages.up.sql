@@ -0,0 +1,3 @@
+-- Points a message earned its author, so edits can be re-scored and purges reversed.
+-- NULL means the message never went through scoring (e.g. it was sent during the cooldown).
+ALTER TABLE "messages" ADD COLUMN "points" REAL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "topics";
+DROP INDEX IF EXISTS "idx_point_history_chat_thread";
+ALTER TABLE "point_history" DROP COLUMN "thread_id";
@@ -0,0 +1,12 @@
+-- Forum topic the points were earned in, 0 outside of topics
+ALTER TABLE "point_history" ADD COLUMN "thread_id" INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS "idx_point_history_chat_thread" ON "point_history" ("chat_id", "thread_id", "timestamp");
+
+CREATE TABLE IF NOT EXISTS "topics" (
+	"chat_id" INTEGER NOT NULL,
+	"thread_id" INTEGER NOT NULL,
+	"multiplier" REAL NOT NULL DEFAULT 1, -- Applied to points earned by messages in the topic
+	"updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("chat_id", "thread_id")
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "event_schedules";
+DROP TABLE IF EXISTS "events";
@@ -0,0 +1,28 @@
+-- One-off chat-wide point multiplier events
+CREATE TABLE IF NOT EXISTS "events" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"chat_id" INTEGER NOT NULL,
+	"multiplier" REAL NOT NULL,
+	"starts_at" TIMESTAMP NOT NULL,
+	"ends_at" TIMESTAMP NOT NULL,
+	"created_by" INTEGER NOT NULL,
+	"end_announced" INTEGER NOT NULL DEFAULT 0, -- 1 once the end of the event was posted in the chat
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_events_chat_ends_at" ON "events" ("chat_id", "ends_at");
+
+-- Weekly recurring events, e.g. every Friday from 20:00 to 22:00
+CREATE TABLE IF NOT EXISTS "event_schedules" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"chat_id" INTEGER NOT NULL,
+	"weekday" INTEGER NOT NULL, -- 0 is Sunday
+	"start_time" TEXT NOT NULL, -- HH:MM in bot.events.timezone
+	"end_time" TEXT NOT NULL,   -- HH:MM, before start_time when the event runs past midnight
+	"multiplier" REAL NOT NULL,
+	"created_by" INTEGER NOT NULL,
+	"active" INTEGER NOT NULL DEFAULT 0, -- 1 while the start was announced and the end was not yet
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_event_schedules_chat" ON "event_schedules" ("chat_id");
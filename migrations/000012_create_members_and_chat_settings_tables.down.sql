@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "chat_settings";
+DROP TABLE IF EXISTS "members";
@@ -0,0 +1,19 @@
+-- Members who joined while the bot was watching, used for the newcomer probation
+CREATE TABLE IF NOT EXISTS "members" (
+	"chat_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"joined_at" TIMESTAMP,                     -- NULL when the member joined before the bot was added
+	"messages" INTEGER NOT NULL DEFAULT 0,     -- Messages sent since joining
+	"trusted" INTEGER NOT NULL DEFAULT 0,      -- 1 when an admin let the member skip the probation
+	PRIMARY KEY("chat_id", "user_id")
+);
+
+-- Per-chat settings, NULL columns fall back to the config file
+CREATE TABLE IF NOT EXISTS "chat_settings" (
+	"chat_id" INTEGER NOT NULL,
+	"probation_hours" INTEGER,
+	"probation_messages" INTEGER,
+	"probation_rate" REAL,
+	"updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("chat_id")
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "warns" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"chat_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,  -- The warned user
+	"admin_id" INTEGER NOT NULL, -- The admin who issued the warning
+	"reason" TEXT NOT NULL DEFAULT '',
+	"created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_warns_chat_user" ON "warns" ("chat_id", "user_id");
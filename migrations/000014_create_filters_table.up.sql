@@ -0,0 +1,15 @@
+-- Blocklist rules admins set up with /filter, checked on every message
+CREATE TABLE IF NOT EXISTS "filters" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"chat_id" INTEGER NOT NULL,
+	"kind" TEXT NOT NULL,                  -- word, regex, invite or domain
+	"pattern" TEXT NOT NULL DEFAULT '',    -- Empty for invite filters
+	"action" TEXT NOT NULL,                -- delete, warn, mute or deduct
+	"duration" TEXT NOT NULL DEFAULT '',   -- How long mute actions last, empty for forever
+	"amount" REAL NOT NULL DEFAULT 0,      -- Points deduct actions take
+	"created_by" INTEGER NOT NULL,
+	"created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_filters_chat" ON "filters" ("chat_id");
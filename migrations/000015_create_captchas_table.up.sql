@@ -0,0 +1,9 @@
+-- Join captchas newcomers have not solved yet
+CREATE TABLE IF NOT EXISTS "captchas" (
+	"chat_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"message_id" INTEGER NOT NULL,  -- The challenge posted by the bot
+	"answer" TEXT NOT NULL,         -- Callback answer that solves the challenge
+	"expires_at" TIMESTAMP NOT NULL,
+	PRIMARY KEY("chat_id", "user_id")
+);
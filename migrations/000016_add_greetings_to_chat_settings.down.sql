@@ -0,0 +1,5 @@
+ALTER TABLE "chat_settings" DROP COLUMN "rules_link";
+ALTER TABLE "chat_settings" DROP COLUMN "greetings_delete_after";
+ALTER TABLE "chat_settings" DROP COLUMN "greetings_enabled";
+ALTER TABLE "chat_settings" DROP COLUMN "goodbye_text";
+ALTER TABLE "chat_settings" DROP COLUMN "welcome_text";
@@ -0,0 +1,6 @@
+-- Welcome and goodbye messages, NULL columns fall back to the config file
+ALTER TABLE "chat_settings" ADD COLUMN "welcome_text" TEXT;
+ALTER TABLE "chat_settings" ADD COLUMN "goodbye_text" TEXT;
+ALTER TABLE "chat_settings" ADD COLUMN "greetings_enabled" INTEGER;
+ALTER TABLE "chat_settings" ADD COLUMN "greetings_delete_after" INTEGER; -- Seconds, 0 keeps them
+ALTER TABLE "chat_settings" ADD COLUMN "rules_link" TEXT;
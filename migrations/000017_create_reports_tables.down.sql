@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "report_subscribers";
+DROP TABLE IF EXISTS "reports";
@@ -0,0 +1,24 @@
+-- Messages members reported to the admins with /report
+CREATE TABLE IF NOT EXISTS "reports" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"chat_id" INTEGER NOT NULL,
+	"message_id" INTEGER NOT NULL,            -- The reported message
+	"user_id" INTEGER NOT NULL,               -- Author of the reported message
+	"reporter_id" INTEGER NOT NULL,
+	"reason" TEXT NOT NULL DEFAULT '',
+	"status" TEXT NOT NULL DEFAULT 'open',    -- open, deleted, warned, muted or dismissed
+	"handled_by" INTEGER,                     -- The admin who handled the report
+	"created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	"handled_at" TIMESTAMP,
+	PRIMARY KEY("id")
+);
+
+-- A message can only be reported once
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reports_chat_message" ON "reports" ("chat_id", "message_id");
+
+-- Admins who get new reports of a chat in private messages
+CREATE TABLE IF NOT EXISTS "report_subscribers" (
+	"chat_id" INTEGER NOT NULL,
+	"admin_id" INTEGER NOT NULL,
+	PRIMARY KEY("chat_id", "admin_id")
+);
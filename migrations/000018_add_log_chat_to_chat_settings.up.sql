@@ -0,0 +1,2 @@
+-- Channel the bot posts a record of every admin action to, 0 or NULL for none
+ALTER TABLE "chat_settings" ADD COLUMN "log_chat_id" INTEGER;
@@ -0,0 +1,14 @@
+-- Every privileged command and every sanction the bot applied on its own
+CREATE TABLE IF NOT EXISTS "audit_log" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"chat_id" INTEGER NOT NULL,
+	"actor_id" INTEGER NOT NULL,              -- The admin, 0 when the bot acted on its own
+	"target_id" INTEGER NOT NULL DEFAULT 0,   -- The member it was done to, 0 for chat settings
+	"action" TEXT NOT NULL,
+	"params" TEXT NOT NULL DEFAULT '{}',      -- JSON object of the amount, duration and details
+	"reason" TEXT NOT NULL DEFAULT '',
+	"created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_audit_log_chat_target" ON "audit_log" ("chat_id", "target_id");
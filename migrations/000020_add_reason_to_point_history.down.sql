@@ -0,0 +1 @@
+ALTER TABLE "point_history" DROP COLUMN "reason";
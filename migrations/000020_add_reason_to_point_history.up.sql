@@ -0,0 +1,2 @@
+-- Why points were taken, e.g. the reason given with /seize
+ALTER TABLE "point_history" ADD COLUMN "reason" TEXT NOT NULL DEFAULT '';
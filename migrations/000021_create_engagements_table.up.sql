@@ -0,0 +1,10 @@
+-- Rewarded replies from one member to another per day, so bot.engagement.pairDailyCap
+-- holds across restarts
+CREATE TABLE IF NOT EXISTS "engagements" (
+	"chat_id" INTEGER NOT NULL,
+	"day" DATE NOT NULL,
+	"replier_id" INTEGER NOT NULL,
+	"author_id" INTEGER NOT NULL,
+	"count" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("chat_id", "day", "replier_id", "author_id")
+);
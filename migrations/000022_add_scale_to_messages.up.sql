@@ -0,0 +1,3 @@
+-- Multiplier a message was scored with (throttle, topic, probation, duplicate, boost
+-- and event), so edits are re-scored the same way. NULL for messages scored before.
+ALTER TABLE "messages" ADD COLUMN "scale" REAL;
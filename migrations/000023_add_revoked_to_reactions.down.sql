@@ -0,0 +1 @@
+ALTER TABLE "reactions" DROP COLUMN "revoked";
@@ -0,0 +1,3 @@
+-- Removed reactions are kept and flagged instead of deleted, so they still count
+-- toward bot.reactions.messageCap and reactorDailyCap and can't be rewarded again.
+ALTER TABLE "reactions" ADD COLUMN "revoked" INTEGER NOT NULL DEFAULT 0;
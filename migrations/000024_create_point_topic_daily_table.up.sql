@@ -0,0 +1,19 @@
+-- Per-user daily totals of each forum topic, so topic rankings survive the
+-- pruning of raw point history the same way chat rankings do.
+CREATE TABLE IF NOT EXISTS "point_topic_daily" (
+	"chat_id" INTEGER NOT NULL,
+	"thread_id" INTEGER NOT NULL,
+	"day" DATE NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"gained" REAL NOT NULL DEFAULT 0,
+	"lost" REAL NOT NULL DEFAULT 0,
+	PRIMARY KEY("chat_id", "thread_id", "day", "user_id")
+);
+
+INSERT INTO point_topic_daily (chat_id, thread_id, day, user_id, gained, lost)
+SELECT chat_id, thread_id, DATE(timestamp), user_id,
+       SUM(CASE WHEN change = 'gain' THEN amount ELSE 0 END),
+       SUM(CASE WHEN change = 'loss' THEN amount ELSE 0 END)
+FROM point_history
+WHERE thread_id != 0
+GROUP BY chat_id, thread_id, DATE(timestamp), user_id;
@@ -0,0 +1,3 @@
+ALTER TABLE "members" DROP COLUMN "captcha_bonus";
+ALTER TABLE "captchas" DROP COLUMN "restricted_until";
+ALTER TABLE "captchas" DROP COLUMN "restriction";
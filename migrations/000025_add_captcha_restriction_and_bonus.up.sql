@@ -0,0 +1,7 @@
+-- Restriction a newcomer already had before the captcha muted them, restored
+-- instead of the default permissions once the captcha is settled
+ALTER TABLE "captchas" ADD COLUMN "restriction" TEXT NOT NULL DEFAULT '';  -- JSON permissions, empty when unrestricted
+ALTER TABLE "captchas" ADD COLUMN "restricted_until" INTEGER NOT NULL DEFAULT 0; -- Unix time, 0 for forever
+
+-- 1 once the member got bot.captcha.bonus, so rejoining can't earn it again
+ALTER TABLE "members" ADD COLUMN "captcha_bonus" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migration files so the bot binary can
+// migrate its database regardless of the directory it is started from.
+package migrations
+
+import "embed"
+
+// FS holds every *.up.sql and *.down.sql file in this directory.
+//
+//go:embed *.sql
+var FS embed.FS